@@ -0,0 +1,149 @@
+package FastGo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// acmeChallengePrefix HTTP-01 验证请求的路径前缀
+const acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+// CertCache ACME 证书缓存，可使用 DirCertCache、NewMemoryCertCache 或自定义实现
+type CertCache = autocert.Cache
+
+// ErrCertCacheMiss 缓存中不存在指定证书时 CertCache.Get 返回的错误
+var ErrCertCacheMiss = autocert.ErrCacheMiss
+
+// DirCertCache 返回保存在 dir 目录中的证书缓存，目录不存在时自动创建
+func DirCertCache(dir string) CertCache {
+	return autocert.DirCache(dir)
+}
+
+// MemoryCertCache 保存在内存中的证书缓存，进程退出后丢失，适合测试
+type MemoryCertCache struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryCertCache 创建内存证书缓存
+func NewMemoryCertCache() *MemoryCertCache {
+	return &MemoryCertCache{data: make(map[string][]byte)}
+}
+
+// Get 返回 key 对应的数据，不存在时返回 ErrCertCacheMiss
+func (m *MemoryCertCache) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.data[key]
+	if !ok {
+		return nil, ErrCertCacheMiss
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Put 保存 key 对应的数据
+func (m *MemoryCertCache) Put(_ context.Context, key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Delete 删除 key 对应的数据
+func (m *MemoryCertCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+// acmeConfig ACME 自动证书配置
+type acmeConfig struct {
+	directoryURL string       // ACME 目录地址，默认 Let's Encrypt 生产环境
+	email        string       // 账户联系邮箱
+	cache        CertCache    // 证书缓存，默认保存在用户缓存目录
+	httpClient   *http.Client // 访问 ACME 服务器的客户端
+	httpsAddr    string       // HTTPS 监听地址，同时处理 TLS-ALPN-01 验证
+	httpAddr     string       // HTTP 监听地址，处理 HTTP-01 验证并重定向到 HTTPS
+}
+
+// defaultACMECacheDir ACME 证书默认缓存目录
+func defaultACMECacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "FastGo", "acme")
+}
+
+// newManager 按配置创建只为 domains 申请证书的 autocert.Manager
+func (cfg *acmeConfig) newManager(domains []string) *autocert.Manager {
+	cache := cfg.cache
+	if cache == nil {
+		cache = DirCertCache(defaultACMECacheDir())
+	}
+	directoryURL := cfg.directoryURL
+	if directoryURL == "" {
+		directoryURL = autocert.DefaultACMEDirectory
+	}
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      cache,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Email:      cfg.email,
+		Client: &acme.Client{
+			DirectoryURL: directoryURL,
+			HTTPClient:   cfg.httpClient,
+		},
+	}
+}
+
+// ServeAutoTLS 通过 ACME 为 domains 自动申请和续期证书，并启动 HTTPS 服务，行为同 Serve
+// HTTPS 监听器（默认 :443）处理 TLS-ALPN-01 验证，HTTP 监听器（默认 :80）处理 HTTP-01 验证，
+// 其余 HTTP 请求重定向到 HTTPS。监听地址通过 WithAutoTLSAddrs 修改
+func (h *App) ServeAutoTLS(ctx context.Context, domains ...string) error {
+	if len(domains) == 0 {
+		return errors.New("auto TLS requires at least one domain")
+	}
+	_, port, err := parseAddress(h.acme.httpsAddr, true)
+	if err != nil {
+		return err
+	}
+
+	h.acmeManager = h.acme.newManager(domains)
+	directoryURL := h.acmeManager.Client.DirectoryURL
+	h.logger.Info("ACME certificates for %s from %s", strings.Join(domains, ", "), directoryURL)
+
+	https := &endpoint{addr: h.acme.httpsAddr, tls: true, tlsConfig: h.acmeManager.TLSConfig()}
+	redirect := &endpoint{addr: h.acme.httpAddr, redirect: port}
+	return h.serveEndpoints(ctx, https, redirect)
+}
+
+// RunAutoTLS 通过 ACME 自动申请证书并启动 HTTPS 服务，阻塞直到收到 SIGINT/SIGTERM
+func (h *App) RunAutoTLS(domains ...string) {
+	ctx, stop := signalContext(h.logger)
+	defer stop()
+	if err := h.ServeAutoTLS(ctx, domains...); err != nil {
+		h.logger.Error("Server failed to start (AutoTLS): %v", err)
+	}
+}
+
+// acmeChallenge 返回应答 HTTP-01 验证请求的处理器，非验证请求交给后续处理器
+func (h *App) acmeChallenge() HandlerFunc {
+	handler := h.acmeManager.HTTPHandler(nil)
+	return func(c *Context) {
+		if c.request.TLS != nil || !strings.HasPrefix(c.request.URL.Path, acmeChallengePrefix) {
+			return
+		}
+		handler.ServeHTTP(c.writer, c.request)
+		c.Abort()
+	}
+}
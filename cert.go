@@ -0,0 +1,260 @@
+package FastGo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCertCheckInterval 检查证书文件是否变化的默认间隔
+const defaultCertCheckInterval = 10 * time.Second
+
+// CertStore 证书仓库，按 SNI 主机名选择证书，并在证书文件变化时自动重新加载
+// 未匹配到主机名（或客户端未发送 SNI）时使用第一个添加的证书
+type CertStore struct {
+	mu            sync.RWMutex
+	hosts         map[string]*certEntry // 小写主机名，支持 *.example.com 通配
+	fallback      *certEntry
+	checkInterval time.Duration
+	logger        Logger
+}
+
+// certEntry 一张证书及其来源文件
+type certEntry struct {
+	certFile string // 为空表示内存证书，不重新加载
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time // 证书和私钥文件中较新的修改时间
+	checked time.Time // 上次检查文件的时间
+}
+
+// NewCertStore 创建证书仓库
+func NewCertStore() *CertStore {
+	return &CertStore{
+		hosts:         make(map[string]*certEntry),
+		checkInterval: defaultCertCheckInterval,
+		logger:        defaultLogger,
+	}
+}
+
+// SetCheckInterval 设置检查证书文件变化的间隔
+func (s *CertStore) SetCheckInterval(interval time.Duration) *CertStore {
+	if interval > 0 {
+		s.checkInterval = interval
+	}
+	return s
+}
+
+// SetLogger 设置证书重新加载时使用的日志器
+func (s *CertStore) SetLogger(logger Logger) *CertStore {
+	if logger != nil {
+		s.mu.Lock()
+		s.logger = logger
+		s.mu.Unlock()
+	}
+	return s
+}
+
+// AddFile 从文件加载证书，hosts 为空时使用证书中的 DNS 名称（SAN）或 CN
+// 文件修改后（如 cert-manager 轮换证书）会在下一次握手时重新加载，加载失败则继续使用旧证书
+func (s *CertStore) AddFile(certFile, keyFile string, hosts ...string) error {
+	entry := &certEntry{certFile: certFile, keyFile: keyFile}
+	if err := entry.load(); err != nil {
+		return err
+	}
+	s.add(entry, hosts)
+	return nil
+}
+
+// AddCertificate 添加内存中的证书，hosts 规则同 AddFile
+func (s *CertStore) AddCertificate(cert tls.Certificate, hosts ...string) error {
+	if cert.Leaf == nil && len(cert.Certificate) > 0 {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return err
+		}
+		cert.Leaf = leaf
+	}
+	s.add(&certEntry{cert: &cert}, hosts)
+	return nil
+}
+
+func (s *CertStore) add(entry *certEntry, hosts []string) {
+	if len(hosts) == 0 && entry.cert.Leaf != nil {
+		hosts = entry.cert.Leaf.DNSNames
+		if len(hosts) == 0 && entry.cert.Leaf.Subject.CommonName != "" {
+			hosts = []string{entry.cert.Leaf.Subject.CommonName}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, host := range hosts {
+		s.hosts[strings.ToLower(host)] = entry
+	}
+	if s.fallback == nil {
+		s.fallback = entry
+	}
+}
+
+// GetCertificate 实现 tls.Config.GetCertificate：精确匹配 > 通配符匹配 > 默认证书
+func (s *CertStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	entry := s.lookup(strings.ToLower(strings.TrimSuffix(hello.ServerName, ".")))
+	interval, logger := s.checkInterval, s.logger
+	s.mu.RUnlock()
+
+	if entry == nil {
+		return nil, errors.New("no certificate available")
+	}
+	return entry.get(interval, logger), nil
+}
+
+func (s *CertStore) lookup(name string) *certEntry {
+	if name != "" {
+		if entry, ok := s.hosts[name]; ok {
+			return entry
+		}
+		if i := strings.IndexByte(name, '.'); i > 0 {
+			if entry, ok := s.hosts["*"+name[i:]]; ok {
+				return entry
+			}
+		}
+	}
+	return s.fallback
+}
+
+// TLSConfig 返回使用该证书仓库的 TLS 配置
+func (s *CertStore) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: s.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+// ClientAuthMode 客户端证书（mTLS）认证模式
+type ClientAuthMode int
+
+const (
+	ClientAuthNone          ClientAuthMode = iota // 不请求客户端证书
+	ClientAuthRequest                             // 请求客户端证书，但不要求也不校验
+	ClientAuthRequire                             // 要求客户端证书，但不校验
+	ClientAuthVerifyIfGiven                       // 客户端提供证书时用 CA 校验
+	ClientAuthVerify                              // 要求客户端证书并用 CA 校验
+)
+
+// tlsClientAuth 转换为 tls.ClientAuthType
+func (m ClientAuthMode) tlsClientAuth() tls.ClientAuthType {
+	switch m {
+	case ClientAuthRequest:
+		return tls.RequestClientCert
+	case ClientAuthRequire:
+		return tls.RequireAnyClientCert
+	case ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven
+	case ClientAuthVerify:
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.NoClientCert
+	}
+}
+
+// LoadCertPool 从 PEM 文件加载 CA 证书池，用于校验客户端证书
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", file)
+		}
+	}
+	return pool, nil
+}
+
+// certSANs 返回证书的所有 SAN
+func certSANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses)+len(cert.URIs))
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	return sans
+}
+
+// spiffeID 返回证书中的 SPIFFE ID，按规范一张证书最多包含一个
+func spiffeID(cert *x509.Certificate) string {
+	for _, uri := range cert.URIs {
+		if strings.EqualFold(uri.Scheme, "spiffe") {
+			return uri.String()
+		}
+	}
+	return ""
+}
+
+// load 从文件加载证书
+func (e *certEntry) load() error {
+	modTime, err := latestModTime(e.certFile, e.keyFile)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(e.certFile, e.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate %s: %w", e.certFile, err)
+	}
+	e.cert = &cert
+	e.modTime = modTime
+	e.checked = time.Now()
+	return nil
+}
+
+// get 返回当前证书，距上次检查超过 interval 时检查文件是否变化
+func (e *certEntry) get(interval time.Duration, logger Logger) *tls.Certificate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.certFile == "" || time.Since(e.checked) < interval {
+		return e.cert
+	}
+
+	e.checked = time.Now()
+	modTime, err := latestModTime(e.certFile, e.keyFile)
+	if err != nil || !modTime.After(e.modTime) {
+		return e.cert
+	}
+
+	if err := e.load(); err != nil {
+		// 证书可能正在写入，保留旧证书等待下次检查
+		logger.Error("Reload certificate failed: %v", err)
+		return e.cert
+	}
+	logger.Info("Reloaded certificate %s", e.certFile)
+	return e.cert
+}
+
+// latestModTime 返回多个文件中最新的修改时间
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,314 @@
+package FastGo
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultRateWindow 最低吞吐量规则的默认统计窗口
+const defaultRateWindow = 10 * time.Second
+
+// ConnLimiter 连接级限制：总连接数、单 IP 连接数和最低接收速率
+// 同一个 ConnLimiter 可以用于多个监听器，总连接数在这些监听器间共享
+type ConnLimiter struct {
+	maxConnsPerIP int
+	minRate       int64         // 字节/秒，0 表示不限制
+	rateWindow    time.Duration // 低于最低速率持续该时间后关闭连接
+
+	sem chan struct{} // 总连接数信号量
+
+	mu         sync.Mutex
+	perIP      map[string]int
+	conns      map[*limitedConn]struct{}
+	monitoring bool
+
+	active        atomic.Int64
+	accepted      atomic.Int64
+	throttled     atomic.Int64
+	rejectedPerIP atomic.Int64
+	closedSlow    atomic.Int64
+}
+
+// ConnStats 连接计数，用于监控指标
+type ConnStats struct {
+	Active        int64 `json:"active"`          // 当前打开的连接数
+	Accepted      int64 `json:"accepted"`        // 累计接受的连接数
+	Throttled     int64 `json:"throttled"`       // 达到总连接数上限、等待空闲名额的次数
+	RejectedPerIP int64 `json:"rejected_per_ip"` // 超过单 IP 上限被拒绝的连接数
+	ClosedSlow    int64 `json:"closed_slow"`     // 低于最低速率被关闭的连接数
+}
+
+// NewConnLimiter 创建连接限制器，默认不限制
+func NewConnLimiter() *ConnLimiter {
+	return &ConnLimiter{
+		perIP:      make(map[string]int),
+		conns:      make(map[*limitedConn]struct{}),
+		rateWindow: defaultRateWindow,
+	}
+}
+
+// SetMaxConns 设置同时打开的最大连接数，达到上限后暂停接受新连接，直到有连接关闭
+// 需要在启动服务前设置
+func (l *ConnLimiter) SetMaxConns(n int) *ConnLimiter {
+	l.sem = nil
+	if n > 0 {
+		l.sem = make(chan struct{}, n)
+	}
+	return l
+}
+
+// SetMaxConnsPerIP 设置单个远程 IP 同时打开的最大连接数，超过后新连接被直接关闭
+func (l *ConnLimiter) SetMaxConnsPerIP(n int) *ConnLimiter {
+	l.maxConnsPerIP = n
+	return l
+}
+
+// SetMinThroughput 设置接收请求时的最低速率（字节/秒），防御 slowloris 等慢速攻击
+// 连接开始接收请求（或 TLS 握手）后，平均速率低于 bytesPerSec 且持续超过 window 即被关闭；
+// 空闲的 keep-alive 连接和正在执行处理器的请求不受影响，window 为 0 时使用默认的10秒
+func (l *ConnLimiter) SetMinThroughput(bytesPerSec int, window time.Duration) *ConnLimiter {
+	l.minRate = int64(bytesPerSec)
+	if window > 0 {
+		l.rateWindow = window
+	}
+	return l
+}
+
+// Stats 返回连接计数
+func (l *ConnLimiter) Stats() ConnStats {
+	return ConnStats{
+		Active:        l.active.Load(),
+		Accepted:      l.accepted.Load(),
+		Throttled:     l.throttled.Load(),
+		RejectedPerIP: l.rejectedPerIP.Load(),
+		ClosedSlow:    l.closedSlow.Load(),
+	}
+}
+
+// Wrap 返回应用连接限制的监听器
+func (l *ConnLimiter) Wrap(ln net.Listener) net.Listener {
+	return &limitedListener{Listener: ln, limiter: l, done: make(chan struct{})}
+}
+
+// limitedListener 应用 ConnLimiter 的监听器
+type limitedListener struct {
+	net.Listener
+	limiter   *ConnLimiter
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Accept 等待总连接数名额后接受连接，超过单 IP 上限的连接直接关闭并继续等待下一个连接
+func (ln *limitedListener) Accept() (net.Conn, error) {
+	l := ln.limiter
+	for {
+		if l.sem != nil {
+			select {
+			case l.sem <- struct{}{}:
+			default:
+				l.throttled.Add(1)
+				select {
+				case l.sem <- struct{}{}:
+				case <-ln.done:
+					return nil, net.ErrClosed
+				}
+			}
+		}
+
+		conn, err := ln.Listener.Accept()
+		if err != nil {
+			l.releaseSlot()
+			return nil, err
+		}
+
+		ip := remoteIP(conn)
+		if !l.acquireIP(ip) {
+			l.rejectedPerIP.Add(1)
+			_ = conn.Close()
+			l.releaseSlot()
+			continue
+		}
+
+		c := &limitedConn{Conn: conn, limiter: l, ip: ip, state: http.StateNew}
+		l.accepted.Add(1)
+		l.active.Add(1)
+		if l.minRate > 0 {
+			l.track(c)
+		}
+		return c, nil
+	}
+}
+
+func (ln *limitedListener) Close() error {
+	ln.closeOnce.Do(func() {
+		close(ln.done)
+	})
+	return ln.Listener.Close()
+}
+
+func (l *ConnLimiter) releaseSlot() {
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+// acquireIP 占用一个单 IP 名额，非 TCP 连接不限制
+func (l *ConnLimiter) acquireIP(ip string) bool {
+	if l.maxConnsPerIP <= 0 || ip == "" {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.perIP[ip] >= l.maxConnsPerIP {
+		return false
+	}
+	l.perIP[ip]++
+	return true
+}
+
+func (l *ConnLimiter) releaseIP(ip string) {
+	if l.maxConnsPerIP <= 0 || ip == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.perIP[ip] <= 1 {
+		delete(l.perIP, ip)
+		return
+	}
+	l.perIP[ip]--
+}
+
+// track 记录需要检查速率的连接，第一个连接加入时启动检查
+func (l *ConnLimiter) track(c *limitedConn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.conns[c] = struct{}{}
+	if !l.monitoring {
+		l.monitoring = true
+		go l.monitor()
+	}
+}
+
+func (l *ConnLimiter) untrack(c *limitedConn) {
+	l.mu.Lock()
+	delete(l.conns, c)
+	l.mu.Unlock()
+}
+
+// monitor 定期关闭低于最低速率的连接，没有连接时退出
+func (l *ConnLimiter) monitor() {
+	interval := l.rateWindow / 4
+	if interval < 100*time.Millisecond {
+		interval = 100 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		l.mu.Lock()
+		if len(l.conns) == 0 {
+			l.monitoring = false
+			l.mu.Unlock()
+			return
+		}
+		slow := make([]*limitedConn, 0)
+		for c := range l.conns {
+			if c.tooSlow(now, l.minRate, l.rateWindow) {
+				slow = append(slow, c)
+			}
+		}
+		l.mu.Unlock()
+
+		for _, c := range slow {
+			l.closedSlow.Add(1)
+			_ = c.Close()
+		}
+	}
+}
+
+// limitedConn 统计接收字节数的连接，关闭时归还名额
+type limitedConn struct {
+	net.Conn
+	limiter   *ConnLimiter
+	ip        string
+	closeOnce sync.Once
+
+	mu        sync.Mutex
+	state     http.ConnState
+	firstByte time.Time // 本轮请求收到第一个字节的时间
+	received  int64     // 本轮请求收到的字节数
+}
+
+func (c *limitedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 && c.limiter.minRate > 0 {
+		c.mu.Lock()
+		if c.firstByte.IsZero() {
+			c.firstByte = time.Now()
+		}
+		c.received += int64(n)
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+func (c *limitedConn) Close() error {
+	c.closeOnce.Do(func() {
+		l := c.limiter
+		l.active.Add(-1)
+		l.releaseIP(c.ip)
+		l.releaseSlot()
+		if l.minRate > 0 {
+			l.untrack(c)
+		}
+	})
+	return c.Conn.Close()
+}
+
+// setState 连接进入新状态；新建和空闲状态下开始统计下一个请求的接收速率
+func (c *limitedConn) setState(state http.ConnState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state = state
+	if state == http.StateNew || state == http.StateIdle {
+		c.firstByte = time.Time{}
+		c.received = 0
+	}
+}
+
+// tooSlow 正在接收请求且平均速率在 window 以上的时间内低于 minRate
+func (c *limitedConn) tooSlow(now time.Time, minRate int64, window time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if (c.state != http.StateNew && c.state != http.StateIdle) || c.firstByte.IsZero() {
+		return false
+	}
+	elapsed := now.Sub(c.firstByte)
+	if elapsed < window {
+		return false
+	}
+	return c.received*int64(time.Second) < minRate*int64(elapsed)
+}
+
+// trackConnState 将 http.Server 的连接状态同步给 ConnLimiter 包装的连接
+func trackConnState(conn net.Conn, state http.ConnState) {
+	if tc, ok := conn.(*tls.Conn); ok {
+		conn = tc.NetConn()
+	}
+	if c, ok := conn.(*limitedConn); ok {
+		c.setState(state)
+	}
+}
+
+// remoteIP 返回 TCP 连接的远程 IP，其他连接返回空字符串
+func remoteIP(conn net.Conn) string {
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		return addr.IP.String()
+	}
+	return ""
+}
@@ -2,11 +2,11 @@ package FastGo
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
-	"log"
 	"mime/multipart"
 	"net"
 	"net/http"
@@ -103,7 +103,6 @@ type Context struct {
 	// 请求信息
 	method    string
 	path      string
-	params    map[string]string
 	query     url.Values
 	clientIP  string
 	userAgent string
@@ -123,7 +122,8 @@ type Context struct {
 	index    int
 
 	// 错误处理
-	errors []error
+	errors        []error
+	errorsHandled int // 已交给 ErrorHandler 的错误数
 
 	// 执行控制
 	aborted bool
@@ -133,18 +133,38 @@ type Context struct {
 	requestID string
 	// 标记响应头是否已写入
 	written bool
+	// 服务器开始优雅关闭时关闭
+	drain <-chan struct{}
+	// 应用的日志器，由 core 设置
+	logger Logger
 
 	// 路由参数
 	Params Params
 }
 
+// Logger 返回应用的日志器，未经过 App 处理的上下文返回默认日志器
+func (c *Context) Logger() Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return defaultLogger
+}
+
+// SetParam 设置路径参数，已存在时覆盖，可通过 GetPathParam 读取
 func (c *Context) SetParam(key string, value string) {
-	c.params[key] = value
+	for i := range c.Params {
+		if c.Params[i].Key == key {
+			c.Params[i].Value = value
+			return
+		}
+	}
+	c.Params = append(c.Params, Param{Key: key, Value: value})
 }
 
+// SetParams 批量设置路径参数
 func (c *Context) SetParams(params Params) {
 	for _, pa := range params {
-		c.params[pa.Key] = pa.Value
+		c.SetParam(pa.Key, pa.Value)
 	}
 }
 
@@ -154,7 +174,6 @@ func NewContext(writer http.ResponseWriter, request *http.Request) *Context {
 		writer:    writer,
 		method:    "",
 		path:      "",
-		params:    make(map[string]string),
 		query:     make(url.Values),
 		headers:   make(map[string]string),
 		handlers:  make([]HandlerFunc, 0),
@@ -193,6 +212,7 @@ func (c *Context) Reset(writer http.ResponseWriter, request *http.Request) {
 	c.written = false
 
 	c.errors = c.errors[:0]
+	c.errorsHandled = 0
 
 	c.Params = c.Params[:0]
 
@@ -236,11 +256,24 @@ func (c *Context) StatusCode() int {
 
 // Write 写入响应体（辅助方法，通常不直接暴露）
 func (c *Context) Write(bytes []byte) (int, error) {
+	c.writeHeader()
+	return c.writer.Write(bytes)
+}
+
+// writeHeader 写入状态码，只执行一次；未设置状态码时为 200
+func (c *Context) writeHeader() {
 	if !c.written {
+		if c.statusCode == 0 {
+			c.statusCode = http.StatusOK
+		}
 		c.writer.WriteHeader(c.statusCode)
 		c.written = true
 	}
-	return c.writer.Write(bytes)
+}
+
+// Written 响应是否已经写入
+func (c *Context) Written() bool {
+	return c.written
 }
 
 // SendString 发送纯文本响应
@@ -249,7 +282,7 @@ func (c *Context) SendString(code int, body string) {
 	c.SetHeader("Content-Type", "text/plain; charset=utf-8")
 	_, err := c.Write([]byte(body))
 	if err != nil {
-		log.Printf("Error writing response: %v", err)
+		c.Logger().Warn("Error writing response: %v", err)
 	}
 }
 
@@ -266,7 +299,7 @@ func (c *Context) SendJson(code int, jsonData JSON) {
 
 	_, err = c.Write(bytes)
 	if err != nil {
-		log.Printf("Error writing response: %v", err)
+		c.Logger().Warn("Error writing response: %v", err)
 	}
 }
 
@@ -276,7 +309,7 @@ func (c *Context) SendHtml(code int, html string) {
 	c.SetHeader("Content-Type", "text/html; charset=utf-8")
 	_, err := c.Write([]byte(html))
 	if err != nil {
-		log.Printf("Error writing HTML response: %v", err)
+		c.Logger().Warn("Error writing HTML response: %v", err)
 	}
 }
 
@@ -295,7 +328,7 @@ func (c *Context) SendXml(code int, xmlData interface{}) {
 	xmlResponse := []byte(xml.Header + string(bytes))
 	_, err = c.Write(xmlResponse)
 	if err != nil {
-		log.Printf("Error writing XML response: %v", err)
+		c.Logger().Warn("Error writing XML response: %v", err)
 	}
 }
 
@@ -313,7 +346,7 @@ func (c *Context) JSONP(code int, callback string, data interface{}) {
 	response := fmt.Sprintf("%s(%s)", callback, string(jsonBytes))
 	_, err = c.Write([]byte(response))
 	if err != nil {
-		log.Printf("Error writing JSONP response: %v", err)
+		c.Logger().Warn("Error writing JSONP response: %v", err)
 	}
 }
 
@@ -323,7 +356,7 @@ func (c *Context) Data(code int, contentType string, data []byte) {
 	c.SetHeader("Content-Type", contentType)
 	_, err := c.Write(data)
 	if err != nil {
-		log.Printf("Error writing raw data: %v", err)
+		c.Logger().Warn("Error writing raw data: %v", err)
 	}
 }
 
@@ -480,6 +513,63 @@ func (c *Context) Protocol() string {
 	return "http"
 }
 
+// PeerCertificates 获取客户端提供的证书链（未经校验），非 mTLS 请求返回 nil
+func (c *Context) PeerCertificates() []*x509.Certificate {
+	if c.request.TLS == nil {
+		return nil
+	}
+	return c.request.TLS.PeerCertificates
+}
+
+// VerifiedChain 获取已通过 CA 校验的客户端证书链，未校验时返回 nil
+func (c *Context) VerifiedChain() []*x509.Certificate {
+	if c.request.TLS == nil || len(c.request.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+	return c.request.TLS.VerifiedChains[0]
+}
+
+// ClientCertificate 获取客户端证书，没有时返回 nil
+func (c *Context) ClientCertificate() *x509.Certificate {
+	certs := c.PeerCertificates()
+	if len(certs) == 0 {
+		return nil
+	}
+	return certs[0]
+}
+
+// ClientCertVerified 判断客户端证书是否已通过 CA 校验
+func (c *Context) ClientCertVerified() bool {
+	return len(c.VerifiedChain()) > 0
+}
+
+// ClientCertSubject 获取客户端证书的主题，如 CN=client,O=example
+func (c *Context) ClientCertSubject() string {
+	cert := c.ClientCertificate()
+	if cert == nil {
+		return ""
+	}
+	return cert.Subject.String()
+}
+
+// ClientCertSANs 获取客户端证书的所有 SAN（DNS、邮箱、IP、URI）
+func (c *Context) ClientCertSANs() []string {
+	cert := c.ClientCertificate()
+	if cert == nil {
+		return nil
+	}
+	return certSANs(cert)
+}
+
+// SPIFFEID 获取客户端证书中的 SPIFFE ID（spiffe:// 开头的 URI SAN），没有时返回空字符串
+func (c *Context) SPIFFEID() string {
+	cert := c.ClientCertificate()
+	if cert == nil {
+		return ""
+	}
+	return spiffeID(cert)
+}
+
 // Referer 获取请求来源
 func (c *Context) Referer() string {
 	return c.request.Referer()
@@ -740,6 +830,11 @@ func (c *Context) UserAgent() string {
 	return c.userAgent
 }
 
+// RequestID 获取请求头 X-Request-Id 的值
+func (c *Context) RequestID() string {
+	return c.requestID
+}
+
 // PostForm 获取表单参数
 func (c *Context) PostForm(key string) string {
 	return c.request.FormValue(key)
@@ -1091,7 +1186,18 @@ func HTTPNotFound(c *Context) {
 	c.SetHeader("Content-Type", "text/plain; charset=utf-8")
 	_, err := c.Write([]byte("404 Not Found"))
 	if err != nil {
-		log.Printf("Error writing 404 response: %v", err)
+		c.Logger().Warn("Error writing 404 response: %v", err)
+	}
+	c.Abort()
+}
+
+// HTTPMethodNotAllowed 处理 405 错误，Allow 头由路由器设置
+func HTTPMethodNotAllowed(c *Context) {
+	c.SetStatus(http.StatusMethodNotAllowed)
+	c.SetHeader("Content-Type", "text/plain; charset=utf-8")
+	_, err := c.Write([]byte("405 Method Not Allowed"))
+	if err != nil {
+		c.Logger().Warn("Error writing 405 response: %v", err)
 	}
 	c.Abort()
 }
@@ -1238,7 +1344,7 @@ func (c *Context) MustGet(key interface{}) interface{} {
 	if value, exists := c.Get(key); exists {
 		return value
 	}
-	panic("Key \"" + key.(string) + "\" does not exist")
+	panic(fmt.Sprintf("Key \"%v\" does not exist", key))
 }
 
 // GetString 获取字符串类型的存储数据
@@ -1373,6 +1479,12 @@ func (c *Context) Err() error {
 	return nil
 }
 
+// Draining 返回在服务器开始优雅关闭时关闭的通道
+// SSE、WebSocket 等长连接处理器应监听此通道，尽快结束响应以免被强制中断
+func (c *Context) Draining() <-chan struct{} {
+	return c.drain
+}
+
 // Value 返回与键关联的值
 func (c *Context) Value(key interface{}) interface{} {
 	if c.request.Context() != nil {
@@ -1388,7 +1500,6 @@ func (c *Context) Copy() *Context {
 		writer:    c.writer,
 		method:    c.method,
 		path:      c.path,
-		params:    make(map[string]string),
 		query:     make(url.Values),
 		clientIP:  c.clientIP,
 		userAgent: c.userAgent,
@@ -1404,14 +1515,11 @@ func (c *Context) Copy() *Context {
 		startTime: c.startTime,
 		requestID: c.requestID,
 		written:   c.written,
+		drain:     c.drain,
+		logger:    c.logger,
 		Params:    make(Params, len(c.Params)),
 	}
 
-	// 复制参数映射
-	for k, v := range c.params {
-		cp.params[k] = v
-	}
-
 	// 复制查询参数
 	for k, v := range c.query {
 		cp.query[k] = v
@@ -1519,12 +1627,19 @@ func (c *Context) ServeRange(ctx context.Context, reader RangeReader) {
 		c.SetStatus(http.StatusOK)
 		c.SetHeader("Content-Length", strconv.FormatInt(fileSize, 10))
 
+		// HEAD 请求只返回响应头，不读取数据
+		if c.IsHead() {
+			c.writeHeader()
+			return
+		}
+
 		// 读取完整数据
 		fullReader, _, err := reader.ReadRange(ctx, 0, fileSize-1)
 		if err != nil {
 			c.InternalServerError(fmt.Sprintf("read full data failed: %v", err))
 			return
 		}
+		c.writeHeader()
 		_, err = io.Copy(c.writer, fullReader)
 		if err != nil {
 			return
@@ -1540,12 +1655,18 @@ func (c *Context) ServeRange(ctx context.Context, reader RangeReader) {
 	c.SetHeader("Content-Range", fmt.Sprintf("bytes %d-%d/%d", spec.Start, spec.End, fileSize))
 	c.SetHeader("Content-Length", strconv.FormatInt(spec.Length, 10))
 
+	if c.IsHead() {
+		c.writeHeader()
+		return
+	}
+
 	// 读取指定范围数据并写入响应
 	rangeDataReader, _, err := reader.ReadRange(ctx, spec.Start, spec.End)
 	if err != nil {
 		c.InternalServerError(fmt.Sprintf("read range data failed: %v", err))
 		return
 	}
+	c.writeHeader()
 	_, err = io.Copy(c.writer, rangeDataReader)
 	if err != nil {
 		return
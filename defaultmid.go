@@ -1,7 +1,7 @@
 package FastGo
 
 import (
-	"LogX"
+	"crypto/x509"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -10,7 +10,7 @@ import (
 )
 
 type MiddlewareLog struct {
-	defaultLoggerMid *LogX.AsyncLogger
+	defaultLoggerMid Logger
 }
 
 // HandleHTTP 核心中间件逻辑：采集并打印HTTP请求日志
@@ -47,16 +47,18 @@ func (m *MiddlewareLog) Handle(context *Context) {
 
 // NewMiddlewareLog 创建日志中间件实例（初始化默认日志器）
 func NewMiddlewareLog() *MiddlewareLog {
-	logger := LogX.NewDefaultAsyncLogger("HTTP")
+	logger := NewSlogLogger(nil, "module", "HTTP")
 	return &MiddlewareLog{
 		defaultLoggerMid: logger,
 	}
 }
 
 // SetLogger 自定义日志器（支持外部替换）
-func (m *MiddlewareLog) SetLogger(logger *LogX.AsyncLogger) {
+func (m *MiddlewareLog) SetLogger(logger Logger) {
+	if logger == nil {
+		return
+	}
 	m.defaultLoggerMid = logger
-
 }
 
 type CorsConfig struct {
@@ -313,3 +315,98 @@ func (c *CorsConfig) SetMaxAge(maxAge int) *CorsConfig {
 	c.maxAge = maxAge
 	return c
 }
+
+// ClientCertAuth 按客户端证书身份授权的中间件，用于 mTLS 监听器上的路由组
+// 未配置任何规则时只要求客户端证书通过校验
+type ClientCertAuth struct {
+	allowCommonNames []string
+	allowDNSNames    []string
+	allowSPIFFEIDs   []string // 支持以 /* 结尾的前缀匹配
+	allowFunc        func(*x509.Certificate) bool
+	requireVerified  bool
+}
+
+// NewClientCertAuth 创建客户端证书授权中间件，默认要求证书已通过 CA 校验
+func NewClientCertAuth() *ClientCertAuth {
+	return &ClientCertAuth{
+		requireVerified: true,
+	}
+}
+
+func (a *ClientCertAuth) Handle(ctx *Context) {
+	cert := ctx.ClientCertificate()
+	if cert == nil || (a.requireVerified && !ctx.ClientCertVerified()) {
+		ctx.Unauthorized("Client certificate required")
+		return
+	}
+	if !a.isAllowed(cert) {
+		ctx.Forbidden("Client certificate not allowed")
+		return
+	}
+	ctx.Next()
+}
+
+// isAllowed 检查证书身份是否匹配任一规则
+func (a *ClientCertAuth) isAllowed(cert *x509.Certificate) bool {
+	if len(a.allowCommonNames) == 0 && len(a.allowDNSNames) == 0 && len(a.allowSPIFFEIDs) == 0 && a.allowFunc == nil {
+		return true
+	}
+
+	for _, cn := range a.allowCommonNames {
+		if cert.Subject.CommonName == cn {
+			return true
+		}
+	}
+
+	for _, allowed := range a.allowDNSNames {
+		for _, name := range cert.DNSNames {
+			if strings.EqualFold(name, allowed) {
+				return true
+			}
+		}
+	}
+
+	if id := spiffeID(cert); id != "" {
+		for _, allowed := range a.allowSPIFFEIDs {
+			if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+				if strings.HasPrefix(id, prefix+"/") {
+					return true
+				}
+			} else if id == allowed {
+				return true
+			}
+		}
+	}
+
+	return a.allowFunc != nil && a.allowFunc(cert)
+}
+
+// SetAllowCommonNames 设置允许的证书 CN
+func (a *ClientCertAuth) SetAllowCommonNames(names ...string) *ClientCertAuth {
+	a.allowCommonNames = names
+	return a
+}
+
+// SetAllowDNSNames 设置允许的 DNS SAN
+func (a *ClientCertAuth) SetAllowDNSNames(names ...string) *ClientCertAuth {
+	a.allowDNSNames = names
+	return a
+}
+
+// SetAllowSPIFFEIDs 设置允许的 SPIFFE ID，如 spiffe://example.org/ns/prod/* 匹配该路径下所有身份
+func (a *ClientCertAuth) SetAllowSPIFFEIDs(ids ...string) *ClientCertAuth {
+	a.allowSPIFFEIDs = ids
+	return a
+}
+
+// SetAllowFunc 设置自定义授权函数，其他规则都不匹配时调用
+func (a *ClientCertAuth) SetAllowFunc(fn func(*x509.Certificate) bool) *ClientCertAuth {
+	a.allowFunc = fn
+	return a
+}
+
+// SetRequireVerified 设置是否要求证书已通过 CA 校验
+func (a *ClientCertAuth) SetRequireVerified(require bool) *ClientCertAuth {
+	a.requireVerified = require
+	return a
+}
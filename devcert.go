@@ -0,0 +1,178 @@
+package FastGo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	devCAValidity   = 10 * 365 * 24 * time.Hour
+	devCertValidity = 397 * 24 * time.Hour // 浏览器接受的最长有效期
+	devCertRenew    = 30 * 24 * time.Hour  // 剩余有效期不足时重新签发
+)
+
+// defaultDevCertDir 开发证书默认缓存目录
+func defaultDevCertDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "FastGo", "devcert")
+}
+
+// devCertificate 返回本地开发用的证书，证书由缓存目录中的本地 CA 签发
+// 覆盖 localhost、本机所有 IP 和 extraHosts；缓存的证书即将过期或未覆盖当前地址时重新签发
+func devCertificate(logger Logger, dir string, extraHosts ...string) (tls.Certificate, error) {
+	if dir == "" {
+		dir = defaultDevCertDir()
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return tls.Certificate{}, err
+	}
+
+	caCertPath := filepath.Join(dir, "ca.crt")
+	caKeyPath := filepath.Join(dir, "ca.key")
+	certPath := filepath.Join(dir, "localhost.crt")
+	keyPath := filepath.Join(dir, "localhost.key")
+
+	ca, caKey, err := loadKeyPair(caCertPath, caKeyPath)
+	if err != nil || time.Until(ca.NotAfter) < devCertRenew {
+		ca, caKey, err = createDevCA(caCertPath, caKeyPath)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("create development CA: %w", err)
+		}
+		// 新 CA 签发前的证书全部失效
+		_ = os.Remove(certPath)
+	}
+
+	hosts := append(getAllIPs(), "127.0.0.1", "::1")
+	hosts = append(hosts, extraHosts...)
+
+	leaf, _, err := loadKeyPair(certPath, keyPath)
+	if err != nil || time.Until(leaf.NotAfter) < devCertRenew || !certCovers(leaf, hosts) || leaf.CheckSignatureFrom(ca) != nil {
+		if err := createDevLeaf(certPath, keyPath, hosts, ca, caKey); err != nil {
+			return tls.Certificate{}, fmt.Errorf("create development certificate: %w", err)
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	// 附带 CA 证书，客户端只需信任 CA
+	cert.Certificate = append(cert.Certificate, ca.Raw)
+	logger.Info("Using development certificate %s", certPath)
+	logger.Info("Trust the development CA to avoid browser warnings: %s", caCertPath)
+	return cert, nil
+}
+
+// createDevCA 创建本地开发 CA
+func createDevCA(certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	hostname, _ := os.Hostname()
+	template := &x509.Certificate{
+		SerialNumber:          randomSerial(),
+		Subject:               pkix.Name{Organization: []string{"FastGo development CA"}, CommonName: "FastGo dev CA " + hostname},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(devCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writeKeyPair(certPath, keyPath, der, key); err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	return cert, key, err
+}
+
+// createDevLeaf 用本地 CA 签发覆盖 hosts 的服务端证书
+func createDevLeaf(certPath, keyPath string, hosts []string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber: randomSerial(),
+		Subject:      pkix.Name{Organization: []string{"FastGo development certificate"}, CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(devCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return err
+	}
+	return writeKeyPair(certPath, keyPath, der, key)
+}
+
+// loadKeyPair 读取 PEM 格式的证书和 ECDSA 私钥
+func loadKeyPair(certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not an ECDSA key", keyPath)
+	}
+	return pair.Leaf, key, nil
+}
+
+// writeKeyPair 以 PEM 格式写入证书和私钥，私钥仅当前用户可读
+func writeKeyPair(certPath, keyPath string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// certCovers 判断证书是否覆盖所有主机名和 IP
+func certCovers(cert *x509.Certificate, hosts []string) bool {
+	for _, host := range hosts {
+		if host != "" && cert.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// randomSerial 生成随机证书序列号
+func randomSerial() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return big.NewInt(time.Now().UnixNano())
+	}
+	return serial
+}
@@ -0,0 +1,129 @@
+package FastGo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// HandlerFuncE 返回错误的处理器，错误交给 ErrorHandler 统一转换为响应
+type HandlerFuncE func(*Context) error
+
+// Handle 执行处理器，返回错误时记录到 c.Errors() 并中止后续处理器
+func (h HandlerFuncE) Handle(c *Context) {
+	if err := h(c); err != nil {
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// WrapE 将返回错误的处理器转换为 HandlerFunc，用于注册路由
+func WrapE(h HandlerFuncE) HandlerFunc {
+	return h.Handle
+}
+
+// ErrorHandlerFunc 将请求中的错误转换为响应，同一个错误只交给它一次
+// err 为 c.Errors() 中尚未处理的错误，有多个时为 errors.Join 的结果；响应已写入时只应记录日志
+type ErrorHandlerFunc func(c *Context, err error)
+
+// HTTPError 带状态码的错误，Message 返回给客户端，Cause 为内部原因，只记录日志
+type HTTPError struct {
+	Status  int    // HTTP 状态码
+	Code    string // 业务错误码，为空时不返回
+	Message string // 返回给客户端的信息
+	Cause   error  // 内部原因
+}
+
+// NewHTTPError 创建 HTTPError，message 为空时使用状态码对应的文本
+func NewHTTPError(status int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return &HTTPError{Status: status, Message: message}
+}
+
+// SetCode 设置业务错误码
+func (e *HTTPError) SetCode(code string) *HTTPError {
+	e.Code = code
+	return e
+}
+
+// SetCause 设置内部原因
+func (e *HTTPError) SetCause(err error) *HTTPError {
+	e.Cause = err
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%d %s: %v", e.Status, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+// Unwrap 返回内部原因，支持 errors.Is/As
+func (e *HTTPError) Unwrap() error {
+	return e.Cause
+}
+
+// handleErrors 把 c.Errors() 中尚未处理的错误交给 ErrorHandler
+// recovery 先处理路由处理器的错误，使中间件能记录最终响应
+func (h *App) handleErrors(c *Context) {
+	errs := c.errors[c.errorsHandled:]
+	if len(errs) == 0 {
+		return
+	}
+	c.errorsHandled = len(c.errors)
+	err := errs[0]
+	if len(errs) > 1 {
+		err = errors.Join(errs...)
+	}
+	if h.errorHandler != nil {
+		h.errorHandler(c, err)
+		return
+	}
+	h.defaultErrorHandler(c, err)
+}
+
+// finishErrors 处理器链结束后处理剩余的错误，包括全局中间件在 c.Next() 前后记录的错误
+// 响应已写入时不再调用 ErrorHandler，只记录日志
+func (h *App) finishErrors(c *Context) {
+	errs := c.errors[c.errorsHandled:]
+	if len(errs) == 0 {
+		return
+	}
+	if !c.Written() {
+		h.handleErrors(c)
+		return
+	}
+	c.errorsHandled = len(c.errors)
+	h.logger.Error("Error after response was written: %v [request_id=%s %s %s]",
+		errors.Join(errs...), c.RequestID(), c.Method(), c.Path())
+}
+
+// defaultErrorHandler 默认的错误处理：HTTPError 按其状态码和信息返回 JSON，其他错误返回 500 且不暴露内部信息
+// 5xx 错误记录日志（panic 已在恢复时记录）
+func (h *App) defaultErrorHandler(c *Context, err error) {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		httpErr = NewHTTPError(http.StatusInternalServerError, "")
+	}
+
+	var panicErr *PanicError
+	if httpErr.Status >= http.StatusInternalServerError && !errors.As(err, &panicErr) {
+		h.logger.Error("Request failed: %v [request_id=%s %s %s]", err, c.RequestID(), c.Method(), c.Path())
+	}
+	if c.Written() {
+		return
+	}
+
+	response := JSON{
+		"error":   true,
+		"message": httpErr.Message,
+		"status":  httpErr.Status,
+	}
+	if httpErr.Code != "" {
+		response["code"] = httpErr.Code
+	}
+	c.SendJson(httpErr.Status, response)
+}
@@ -0,0 +1,106 @@
+package FastGo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorsFromGlobalMiddleware(t *testing.T) {
+	calls := 0
+	app := NewFastGo(WithMiddlewares())
+	app.SetErrorHandler(func(c *Context, err error) {
+		calls++
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			c.SendString(httpErr.Status, httpErr.Message)
+			return
+		}
+		c.SendString(http.StatusInternalServerError, err.Error())
+	})
+	app.Use(HandlerFuncE(func(c *Context) error {
+		if c.GetHeader("Authorization") == "" {
+			return NewHTTPError(http.StatusUnauthorized, "")
+		}
+		return nil
+	}))
+	app.Router().GET("/fail", WrapE(func(c *Context) error {
+		return NewHTTPError(http.StatusTeapot, "")
+	}))
+	app.prepare()
+
+	tests := []struct {
+		name   string
+		auth   string
+		status int
+	}{
+		{"middleware error", "", http.StatusUnauthorized},
+		{"handler error", "token", http.StatusTeapot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			app.core.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if calls != 1 {
+				t.Errorf("ErrorHandler called %d times, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestErrorsAfterNext(t *testing.T) {
+	logger := &captureLogger{}
+	calls := 0
+	app := NewFastGo(WithLogger(logger), WithMiddlewares())
+	app.SetErrorHandler(func(c *Context, err error) {
+		calls++
+		c.SendString(http.StatusBadGateway, err.Error())
+	})
+	app.Use(HandlerFunc(func(c *Context) {
+		c.Next()
+		c.Error(errors.New("upstream audit failed"))
+	}))
+	app.Router().GET("/silent", func(c *Context) {})
+	app.Router().GET("/written", func(c *Context) {
+		c.SendString(http.StatusOK, "ok")
+	})
+	app.prepare()
+
+	tests := []struct {
+		path   string
+		status int
+		calls  int
+		logged bool
+	}{
+		{"/silent", http.StatusBadGateway, 1, false},
+		{"/written", http.StatusOK, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			calls = 0
+			logger.lines = nil
+			rec := httptest.NewRecorder()
+			app.core.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if calls != tt.calls {
+				t.Errorf("ErrorHandler called %d times, want %d", calls, tt.calls)
+			}
+			logged := len(logger.lines) == 1 && strings.Contains(logger.lines[0], "upstream audit failed")
+			if logged != tt.logged {
+				t.Errorf("logged = %v (%q), want %v", logged, logger.lines, tt.logged)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package FastGo
 
 import (
-	"LogX"
+	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
@@ -10,26 +12,73 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/crypto/acme/autocert"
 )
 
-var defaultLogger = LogX.NewDefaultSyncLogger("FastGo")
+// defaultShutdownTimeout 优雅关闭时等待请求完成的默认时间
+const defaultShutdownTimeout = 30 * time.Second
 
 type App struct {
 	core        *core
 	router      *Router
 	middlewares []Engine
+	once        sync.Once // 保证处理器链只组装一次
+
+	shutdownTimeout time.Duration // 优雅关闭的最长等待时间
+	socketMode      os.FileMode   // Unix 套接字文件权限
+	devCertDir      string        // 开发证书缓存目录
+
+	serverOptions []func(*http.Server) // 应用到每个监听器服务器的配置
+	endpoints     []*endpoint          // 通过 Listen 注册的监听器
+
+	hotRestart     bool          // 是否在 SIGHUP/SIGUSR2 时热重启
+	upgradeTimeout time.Duration // 热重启时等待新进程就绪的时间
+
+	hooks [hookStageCount][]*hook // 生命周期钩子
+
+	acme        acmeConfig        // ACME 自动证书配置
+	acmeManager *autocert.Manager // ServeAutoTLS 启动后设置
+	quicConfig  *quic.Config      // HTTP/3 监听器的 QUIC 参数
+
+	connLimiter *ConnLimiter        // 监听器默认的连接限制
+	concurrency *ConcurrencyLimiter // 请求并发限制
+
+	logger           Logger           // 框架日志器
+	accessLog        *MiddlewareLog   // 默认的访问日志中间件，使用 WithMiddlewares 时为空
+	recoveryFunc     RecoveryFunc     // 处理器 panic 后的回调，为空时交给 errorHandler
+	errorHandler     ErrorHandlerFunc // 请求错误的统一处理，为空时使用默认处理
+	notFound         HandleChain      // App 级 404 处理器，路由组和路由器都未设置时使用
+	methodNotAllowed HandleChain      // App 级 405 处理器
+	health           *Health          // 调用 Health 后设置
 }
 
-func NewFastGo() *App {
-	router := NewRouter()
-	middlewares := make([]Engine, 0)
-	middlewares = append(middlewares, NewMiddlewareLog())
+// NewFastGo 创建应用实例，可通过 Option 调整服务器参数和默认中间件
+func NewFastGo(opts ...Option) *App {
 	app := &App{
-		core:        newCore(),
-		router:      router,
-		middlewares: middlewares,
+		core:   newCore(),
+		router: NewRouter(),
+
+		shutdownTimeout: defaultShutdownTimeout,
+		socketMode:      defaultSocketMode,
+		upgradeTimeout:  defaultUpgradeTimeout,
+		logger:          defaultLogger,
+
+		acme: acmeConfig{httpsAddr: ":443", httpAddr: ":80"},
+	}
+	for _, opt := range opts {
+		opt(app)
+	}
+	// 未通过 WithMiddlewares 指定时使用默认中间件，访问日志写入应用的日志器
+	if app.middlewares == nil {
+		app.accessLog = NewMiddlewareLog()
+		app.accessLog.SetLogger(moduleLogger(app.logger, "HTTP"))
+		app.middlewares = []Engine{app.accessLog}
 	}
 	return app
 }
@@ -49,82 +98,322 @@ func (h *App) Group(prefix string) *RouteGroup {
 	return h.router.Group(prefix)
 }
 
+// SetShutdownTimeout 设置优雅关闭的最长等待时间，超时后仍未完成的请求会被强制中断
+func (h *App) SetShutdownTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	h.shutdownTimeout = timeout
+}
+
+// InFlight 返回所有监听器上正在处理的请求数
+func (h *App) InFlight() int64 {
+	total := h.core.inflight.Load()
+	for _, ep := range h.endpoints {
+		if ep.core != nil && ep.core != h.core {
+			total += ep.core.inflight.Load()
+		}
+	}
+	return total
+}
+
+// SetLogger 设置框架日志器，启动、关闭、证书等日志和默认中间件的访问日志都会写入该日志器
+func (h *App) SetLogger(logger Logger) {
+	if logger == nil {
+		return
+	}
+	h.logger = logger
+	if h.accessLog != nil {
+		h.accessLog.SetLogger(moduleLogger(logger, "HTTP"))
+	}
+}
+
+// SetErrorHandler 设置请求错误的统一处理，处理器返回的错误和 c.Error 记录的错误都交给它转换为响应
+func (h *App) SetErrorHandler(fn ErrorHandlerFunc) {
+	h.errorHandler = fn
+}
+
+// NotFound 设置 App 级 404 处理器，对所有路由器生效；路由器和路由组的设置优先
+func (h *App) NotFound(handlers ...HandlerFunc) {
+	h.notFound = handlers
+}
+
+// MethodNotAllowed 设置 App 级 405 处理器，对所有路由器生效；执行前已设置 Allow 头
+func (h *App) MethodNotAllowed(handlers ...HandlerFunc) {
+	h.methodNotAllowed = handlers
+}
+
+// routeHandler 返回执行路由器的处理器，未匹配时回退到 App 级 404/405 处理器
+func (h *App) routeHandler(r *Router) HandlerFunc {
+	return func(c *Context) {
+		r.handle(c, h.notFound, h.methodNotAllowed)
+	}
+}
+
+// routeErrors 返回各路由器注册时记录的冲突（关闭 SetPanicOnConflict 时）
+func (h *App) routeErrors(endpoints []*endpoint) error {
+	errs := []error{h.router.Err()}
+	for _, ep := range endpoints {
+		if ep.router != nil && ep.router != h.router {
+			errs = append(errs, ep.router.Err())
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // AddRouter 添加一个完整的路由器
 func (h *App) AddRouter(router *Router) {
 	h.router.MergeRouter(router)
 }
 
+// Serve 在 addr 上启动 HTTP 服务，阻塞直到 ctx 被取消或服务出错
+// addr 支持 host:port、unix:/path.sock 和 systemd:[name]（继承 systemd 传入的套接字）
+// 地址非法、端口绑定失败或服务异常退出时返回错误；ctx 取消后关闭服务并返回 nil
+func (h *App) Serve(ctx context.Context, addr string) error {
+	return h.serveEndpoints(ctx, &endpoint{addr: addr})
+}
+
+// ServeTLS 在 addr 上启动 HTTPS 服务，行为同 Serve
+// 证书文件变化时自动重新加载，无需重启服务；certFile 和 keyFile 都为空时使用自动生成的开发证书
+func (h *App) ServeTLS(ctx context.Context, addr, certFile, keyFile string) error {
+	return h.serveEndpoints(ctx, &endpoint{addr: addr, tls: true, certFile: certFile, keyFile: keyFile})
+}
+
+// ServeTLSConfig 使用指定的 TLS 配置在 addr 上启动 HTTPS 服务，行为同 Serve
+// 配合 CertStore 可以按 SNI 主机名提供多张证书
+func (h *App) ServeTLSConfig(ctx context.Context, addr string, config *tls.Config) error {
+	return h.serveEndpoints(ctx, &endpoint{addr: addr, tls: true, tlsConfig: config})
+}
+
+// ServeListener 在已创建的监听器上启动 HTTP 服务，行为同 Serve
+func (h *App) ServeListener(ctx context.Context, ln net.Listener) error {
+	return h.serveEndpoints(ctx, &endpoint{ln: ln})
+}
+
+// Listener 在已创建的监听器上启动 HTTP 服务并阻塞，直到收到 SIGINT/SIGTERM
+func (h *App) Listener(ln net.Listener) error {
+	ctx, stop := signalContext(h.logger)
+	defer stop()
+	return h.ServeListener(ctx, ln)
+}
+
+// Listen 注册一个监听地址，由 Start/RunAll 统一启动和关闭
+// 默认使用 App 的路由器提供 HTTP 服务，可通过 ListenOption 启用 TLS、指定独立路由器或仅做 HTTPS 重定向
+func (h *App) Listen(addr string, opts ...ListenOption) {
+	ep := &endpoint{addr: addr}
+	for _, opt := range opts {
+		opt(ep)
+	}
+	h.endpoints = append(h.endpoints, ep)
+}
+
+// AddListener 注册一个已创建的监听器，行为同 Listen
+func (h *App) AddListener(ln net.Listener, opts ...ListenOption) {
+	ep := &endpoint{ln: ln}
+	for _, opt := range opts {
+		opt(ep)
+	}
+	h.endpoints = append(h.endpoints, ep)
+}
+
+// Start 同时启动所有通过 Listen 注册的监听器，阻塞直到 ctx 被取消或任一监听器出错
+// 任一监听器绑定失败时关闭已绑定的监听器并返回错误；退出时所有监听器一起优雅关闭
+func (h *App) Start(ctx context.Context) error {
+	if len(h.endpoints) == 0 {
+		return errors.New("no listeners registered, use Listen to add one")
+	}
+	return h.serveEndpoints(ctx, h.endpoints...)
+}
+
+// RunAll 启动所有注册的监听器并阻塞，直到收到 SIGINT/SIGTERM
+func (h *App) RunAll() {
+	ctx, stop := signalContext(h.logger)
+	defer stop()
+	if err := h.Start(ctx); err != nil {
+		h.logger.Error("Server failed to start: %v", err)
+	}
+}
+
+// RunTLS 启动 HTTPS 服务并阻塞，直到收到 SIGINT/SIGTERM
 func (h *App) RunTLS(addr, certFile, keyFile string) {
-	addr, port := parseAddress(addr, true)
-	if addr == "" || port == 0 {
-		_ = defaultLogger.Error("Invalid address: %s", addr)
-		return
+	ctx, stop := signalContext(h.logger)
+	defer stop()
+	if err := h.ServeTLS(ctx, addr, certFile, keyFile); err != nil {
+		h.logger.Error("Server failed to start (TLS): %v", err)
 	}
-	h.core.addHandler(midToHandler(h.middlewares)...)
-	h.core.addHandler(h.router.Handle)
-	h.core.SetCert(certFile, keyFile)
-	if addr == "0.0.0.0" {
-		se := getAllIPs()
-		defaultLogger.Info("Server started at all address (TLS)")
-		for _, addr := range se {
-			defaultLogger.Info("Running https://%s:%d", addr, port)
-		}
-	} else if addr == "localhost" || addr == "127.0.0.1" {
-		defaultLogger.Info("Server started at %s", addr)
-		defaultLogger.Info("Running https://localhost:%d", port)
-	} else {
-		defaultLogger.Info("Server started at %s (TLS)", addr)
-		defaultLogger.Info("Running https://localhost:%d", port)
+}
+
+// Run 启动 HTTP 服务并阻塞，直到收到 SIGINT/SIGTERM
+func (h *App) Run(addr string) {
+	ctx, stop := signalContext(h.logger)
+	defer stop()
+	if err := h.Serve(ctx, addr); err != nil {
+		h.logger.Error("Server failed to start: %v", err)
 	}
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := h.core.listenHTTPS(addr+":"+strconv.Itoa(port), certFile, keyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			_ = defaultLogger.Error("Server failed to start (TLS): %v", err)
-			h.core.Close()
-			return
+}
+
+// prepare 组装处理器链，多次启动时只执行一次
+func (h *App) prepare() {
+	h.once.Do(func() {
+		h.core.addHandler(midToHandler(h.middlewares)...)
+		h.core.addHandler(h.recovery())
+		h.core.onPanic = h.handlePanic
+		h.core.onErrors = h.finishErrors
+		h.core.logger = h.logger
+		if h.acmeManager != nil {
+			h.core.addHandler(h.acmeChallenge())
 		}
-	}()
-	h.gracefulShutdown()
-	wg.Wait()
+		h.core.addHandler(h.routeHandler(h.router))
+		h.core.setLimiter(h.concurrency, h.router)
+	})
 }
 
-func (h *App) Run(addr string) {
+// serveEndpoints 绑定并启动所有监听器，ctx 取消或任一监听器退出时一起优雅关闭
+// 生命周期：OnStart → 绑定监听 → OnReady → 运行 → OnShutdown → 排空请求 → OnStop
+// 关闭超时导致请求被中断时返回 gracefulShutdown 的错误
+func (h *App) serveEndpoints(ctx context.Context, endpoints ...*endpoint) error {
+	h.prepare()
+	if err := h.routeErrors(endpoints); err != nil {
+		return err
+	}
 
-	addr, port := parseAddress(addr, false)
-	if addr == "" || port == 0 {
-		_ = defaultLogger.Error("Invalid address: %s", addr)
-		return
+	// 1. OnStart 钩子失败时不再启动，已执行的初始化交给 OnStop 清理
+	if err := h.runHooks(hookStart); err != nil {
+		return errors.Join(err, h.runHooks(hookStop))
 	}
-	h.core.addHandler(midToHandler(h.middlewares)...)
-	h.core.addHandler(h.router.Handle)
 
-	if addr == "0.0.0.0" {
-		se := getAllIPs()
-		defaultLogger.Info("Server started at all address")
-		for _, addr := range se {
-			defaultLogger.Info("Running http://%s:%d", addr, port)
+	// 2. 先绑定全部地址，任一失败则关闭已绑定的监听器
+	for i, ep := range endpoints {
+		if err := h.bind(ep); err != nil {
+			for _, bound := range endpoints[:i] {
+				bound.close()
+			}
+			return errors.Join(err, h.runHooks(hookStop))
 		}
-	} else if addr == "localhost" || addr == "127.0.0.1" {
-		defaultLogger.Info("Server started at %s", addr)
-		defaultLogger.Info("Running http://localhost:%d", port)
+	}
+
+	// 3. 每个监听器在独立的goroutine中提供服务
+	errCh := make(chan error, len(endpoints))
+	for _, ep := range endpoints {
+		go func(ep *endpoint) {
+			errCh <- ep.serve()
+		}(ep)
+	}
+
+	shutdown := func() error {
+		// 先切换为未就绪，负载均衡在 OnShutdown 和排空期间摘除流量
+		h.markUnready()
+		return errors.Join(h.runHooks(hookShutdown), h.gracefulShutdown(endpointCores(endpoints)))
+	}
+
+	var err error
+	remaining := len(endpoints)
+	if err = h.runHooks(hookReady); err != nil {
+		err = errors.Join(err, shutdown())
 	} else {
-		defaultLogger.Info("Server started at %s", addr)
-		defaultLogger.Info("Running http://%s:%d", addr, port)
+		h.setReady(true)
+		notifyReady()
+
+		// 热重启：新进程接管监听器后，当前进程停止接受连接并排空请求
+		upgraded := make(chan struct{})
+		if h.hotRestart {
+			stop := h.watchUpgrade(endpoints, upgraded)
+			defer stop()
+		}
+
+		select {
+		case err = <-errCh:
+			remaining--
+			if errors.Is(err, http.ErrServerClosed) {
+				err = nil
+			}
+			err = errors.Join(err, shutdown())
+		case <-ctx.Done():
+			err = shutdown()
+		case <-upgraded:
+			err = shutdown()
+		}
 	}
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := h.core.listenHTTP(addr + ":" + strconv.Itoa(port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			defaultLogger.Fatal("Server failed to start: %v", err)
-			h.core.Close()
-			return
+
+	// 4. 等待所有监听器退出
+	for ; remaining > 0; remaining-- {
+		if serveErr := <-errCh; serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) && err == nil {
+			err = serveErr
 		}
-	}()
-	h.gracefulShutdown()
-	wg.Wait()
+	}
+	// http3.Server 关闭时不会关闭传入的 UDP 套接字，排空完成后再关闭
+	for _, ep := range endpoints {
+		if ep.udp != nil {
+			_ = ep.udp.Close()
+		}
+	}
+	return errors.Join(err, h.runHooks(hookStop))
+}
+
+// bind 创建监听器并为其选择处理请求的 core
+func (h *App) bind(ep *endpoint) error {
+	if ep.ln == nil {
+		ln, err := h.listen(ep.addr, ep.tls)
+		if err != nil {
+			return err
+		}
+		ep.ln = ln
+	} else {
+		h.logger.Info("Server started at %s %s", ep.ln.Addr().Network(), ep.ln.Addr())
+	}
+
+	switch {
+	case ep.redirect > 0:
+		ep.core = h.newCore()
+		if h.acmeManager != nil {
+			ep.core.addHandler(h.acmeChallenge())
+		}
+		ep.core.addHandler(redirectHTTPS(ep.redirect))
+	case ep.router != nil:
+		ep.core = h.newCore()
+		ep.core.addHandler(midToHandler(h.middlewares)...)
+		ep.core.addHandler(h.recovery())
+		ep.core.addHandler(h.routeHandler(ep.router))
+		ep.core.setLimiter(h.concurrency, ep.router)
+	default:
+		ep.core = h.core
+	}
+	if ep.limiter == nil {
+		ep.limiter = h.connLimiter
+	}
+	if ep.limiter != nil {
+		ep.core.hookConnState()
+	}
+	if ep.tls {
+		ep.core.SetCert(ep.certFile, ep.keyFile)
+		if err := ep.buildTLSConfig(h.devCertDir, h.logger); err != nil {
+			_ = ep.ln.Close()
+			return err
+		}
+	}
+	if ep.http3 {
+		if !ep.tls {
+			_ = ep.ln.Close()
+			return errors.New("HTTP/3 requires TLS, use ListenTLS with ListenHTTP3")
+		}
+		if err := h.bindHTTP3(ep); err != nil {
+			_ = ep.ln.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// newCore 按 App 的服务器配置创建新的 core，供独立路由器或重定向监听器使用
+func (h *App) newCore() *core {
+	c := newCore()
+	c.onPanic = h.handlePanic
+	c.onErrors = h.finishErrors
+	c.logger = h.logger
+	for _, opt := range h.serverOptions {
+		opt(c.server)
+	}
+	return c
 }
 
 // Use 添加中间件到应用
@@ -133,13 +422,61 @@ func (h *App) Use(middlewares ...Engine) {
 }
 
 // gracefulShutdown 优雅关闭服务器
-func (h *App) gracefulShutdown() {
+// 所有 core 同时停止接受新连接，在 shutdownTimeout 内等待正在处理的请求完成，超时后强制关闭
+func (h *App) gracefulShutdown(cores []*core) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.shutdownTimeout)
+	defer cancel()
+
+	var inflight int64
+	for _, c := range cores {
+		inflight += c.inflight.Load()
+	}
+	h.logger.Info("Waiting for %d in-flight requests (timeout %s)", inflight, h.shutdownTimeout)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		dropped  int64
+		firstErr error
+	)
+	for _, c := range cores {
+		wg.Add(1)
+		go func(c *core) {
+			defer wg.Done()
+			n, err := c.shutdown(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			dropped += n
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		h.logger.Error("Server shutdown timeout, %d requests cut off", dropped)
+		return fmt.Errorf("shutdown: %d in-flight requests cut off: %w", dropped, firstErr)
+	}
+	h.logger.Info("Server shutdown complete")
+	return nil
+}
+
+// signalContext 返回一个在收到 SIGINT/SIGTERM 时取消的上下文
+func signalContext(logger Logger) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	sign := <-sigCh
-	defaultLogger.Info("Receive %s Server shutting down...", sign)
-	h.core.Close()
-	defaultLogger.Info("Server shutdown complete")
+	go func() {
+		defer signal.Stop(sigCh)
+		select {
+		case sign := <-sigCh:
+			logger.Info("Receive %s Server shutting down...", sign)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
 }
 
 type core struct {
@@ -148,40 +485,69 @@ type core struct {
 	contextPool  sync.Pool // 上下文池，复用ctx避免GC
 	cert         string    // TLS证书路径
 	key          string    // TLS密钥路径
+
+	inflight  atomic.Int64  // 正在处理的请求数
+	drain     chan struct{} // 开始优雅关闭时关闭
+	drainOnce sync.Once
+
+	http3 map[int]*http3.Server // 按 TCP 端口记录同端口的 HTTP/3 服务器
+
+	limiter    *ConcurrencyLimiter // 请求并发限制，为空时不限制
+	priorities *Router             // 按路由组前缀确定请求优先级
+
+	onPanic  func(c *Context, v any, useCallback bool) // 处理中间件中未被捕获的 panic
+	onErrors func(c *Context)                          // 处理器链结束后处理尚未交给 ErrorHandler 的错误
+	logger   Logger                                    // 请求上下文使用的日志器
+
+	connStateOnce sync.Once
 }
 
 func newCore() *core {
-	return &core{
+	c := &core{
 		server: &http.Server{
-			Handler:        nil,
 			ReadTimeout:    10 * time.Second,
 			WriteTimeout:   10 * time.Second,
 			IdleTimeout:    30 * time.Second,
 			MaxHeaderBytes: 1 << 20, // 1MB
 		},
 		handlerChain: nil,
+		drain:        make(chan struct{}),
 		contextPool: sync.Pool{
 			New: func() interface{} {
 				return NewContext(nil, nil)
 			},
 		},
 	}
+	c.server.Handler = c
+	return c
 }
 
-func (s *core) listenHTTP(addr string) error {
-	s.server.Addr = addr
-	s.server.Handler = s
-	return s.server.ListenAndServe()
-}
-
-func (s *core) listenHTTPS(addr, certFile, keyFile string) error {
-	s.server.Addr = addr
-	s.server.Handler = s
-	return s.server.ListenAndServeTLS(certFile, keyFile)
+// serve 在监听器上提供 HTTP 服务，同一个 core 可以同时服务多个监听器
+func (s *core) serve(ln net.Listener) error {
+	return s.server.Serve(ln)
 }
 
 // ServeHTTP 单routine处理HTTP请求
 func (s *core) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	s.inflight.Add(1)
+	defer s.inflight.Add(-1)
+
+	if s.limiter != nil {
+		release, ok := s.limiter.acquire(request.Context(), s.priorities.priorityOf(request.URL.Path))
+		if !ok {
+			s.limiter.reject(writer)
+			return
+		}
+		defer release()
+	}
+
+	// HEAD 请求丢弃响应体，保留 Content-Length 等响应头；在归还 ctx 之后写入响应头
+	if request.Method == http.MethodHead {
+		hw := newHeadWriter(writer)
+		writer = hw
+		defer hw.finish()
+	}
+
 	// 1. 从对象池获取ctx，失败则新建（兜底）
 	ctx, ok := s.contextPool.Get().(*Context)
 	if !ok || ctx == nil {
@@ -189,15 +555,43 @@ func (s *core) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	} else {
 		ctx.Reset(writer, request)
 	}
+	ctx.drain = s.drain
+	ctx.logger = s.logger
+	if s.http3 != nil {
+		s.setAltSvc(writer, request)
+	}
 
 	// 2. 设置处理器链
 	ctx.SetHandles(s.handlerChain)
 
-	// 3. 直接在当前goroutine中执行处理器链（单routine模式）
+	// 3. 处理完成或发生 panic 后处理剩余的错误，并归还context到池中
+	defer func() {
+		if v := recover(); v != nil {
+			s.recoverPanic(ctx, v)
+		}
+		if s.onErrors != nil {
+			s.onErrors(ctx)
+		}
+		s.contextPool.Put(ctx)
+	}()
+
+	// 4. 直接在当前goroutine中执行处理器链（单routine模式）
 	ctx.Next()
+}
+
+// recoverPanic 处理路由处理器之外（中间件或恢复回调自身）的 panic，不再调用恢复回调
+func (s *core) recoverPanic(ctx *Context, v any) {
+	if s.onPanic == nil || v == http.ErrAbortHandler {
+		s.contextPool.Put(ctx)
+		panic(v)
+	}
+	s.onPanic(ctx, v, false)
+}
 
-	// 4. 处理完成后归还context到池中
-	s.contextPool.Put(ctx)
+// setLimiter 设置请求并发限制，router 提供路由组的优先级
+func (s *core) setLimiter(limiter *ConcurrencyLimiter, router *Router) {
+	s.limiter = limiter
+	s.priorities = router
 }
 
 func (s *core) SetCert(cert, key string) {
@@ -205,11 +599,73 @@ func (s *core) SetCert(cert, key string) {
 	s.key = key
 }
 
+// hookConnState 在服务器的 ConnState 回调中同步连接状态给 ConnLimiter，保留用户设置的回调
+func (s *core) hookConnState() {
+	s.connStateOnce.Do(func() {
+		next := s.server.ConnState
+		s.server.ConnState = func(conn net.Conn, state http.ConnState) {
+			trackConnState(conn, state)
+			if next != nil {
+				next(conn, state)
+			}
+		}
+	})
+}
+
 func (s *core) addHandler(handler ...HandlerFunc) {
 	s.handlerChain = append(s.handlerChain, handler...)
 }
 
+// shutdown 停止接受新连接并等待正在处理的请求完成
+// http.Server.Shutdown 不会等待被劫持的连接（如 WebSocket），因此再按 inflight 计数等待处理器返回；
+// 超过 ctx 截止时间后强制关闭剩余连接，返回被中断的请求数
+func (s *core) shutdown(ctx context.Context) (int64, error) {
+	s.drainOnce.Do(func() {
+		close(s.drain)
+	})
+
+	// HTTP/3 服务器与 TCP 服务器同时关闭，向客户端发送 GOAWAY
+	h3Errs := make(chan error, len(s.http3))
+	for _, server := range s.http3 {
+		go func(server *http3.Server) {
+			h3Errs <- server.Shutdown(ctx)
+		}(server)
+	}
+	err := s.server.Shutdown(ctx)
+	for range s.http3 {
+		if h3Err := <-h3Errs; h3Err != nil && err == nil {
+			err = h3Err
+		}
+	}
+	if err == nil {
+		err = s.waitIdle(ctx)
+	}
+	if err != nil {
+		dropped := s.inflight.Load()
+		s.Close()
+		return dropped, err
+	}
+	return 0, nil
+}
+
+// waitIdle 轮询等待所有处理器返回
+func (s *core) waitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for s.inflight.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
 func (s *core) Close() {
+	for _, server := range s.http3 {
+		_ = server.Close()
+	}
 	err := s.server.Close()
 	if err != nil {
 		return
@@ -224,30 +680,27 @@ func midToHandler(middlewares []Engine) []HandlerFunc {
 	return handlers
 }
 
-func parseAddress(addr string, https bool) (host string, port int) {
+func parseAddress(addr string, https bool) (host string, port int, err error) {
 	// 1. 处理空地址，设置默认值
 	if addr == "" {
 		if https {
-			return "0.0.0.0", 443
+			return "0.0.0.0", 443, nil
 		}
-		return "0.0.0.0", 80
+		return "0.0.0.0", 80, nil
 	}
 
 	host, portStr, err := net.SplitHostPort(addr)
 	if err != nil {
-		_ = defaultLogger.Error("Invalid address: %v", err)
-		return "", 0
+		return "", 0, fmt.Errorf("invalid address %q: %w", addr, err)
 	}
 
 	// 3. 校验并转换端口
 	port, err = strconv.Atoi(portStr)
 	if err != nil {
-		_ = defaultLogger.Error("Invalid port: %v", err)
-		return "", 0
+		return "", 0, fmt.Errorf("invalid port %q: %w", portStr, err)
 	}
 	if port < 0 || port > 65535 {
-		_ = defaultLogger.Error("port out of range (0-65535): %d", port)
-		return "", 0
+		return "", 0, fmt.Errorf("port out of range (0-65535): %d", port)
 	}
 
 	// 4. 处理 Host 为空的情况（例如 ":8080"）
@@ -255,8 +708,29 @@ func parseAddress(addr string, https bool) (host string, port int) {
 		host = "0.0.0.0"
 	}
 
-	return host, port
+	return host, port, nil
+}
+
+// printAddress 打印服务访问地址
+func printAddress(logger Logger, scheme, host string, port int) {
+	suffix := ""
+	if scheme == "https" {
+		suffix = " (TLS)"
+	}
+	if host == "0.0.0.0" {
+		logger.Info("Server started at all address%s", suffix)
+		for _, ip := range getAllIPs() {
+			logger.Info("Running %s://%s:%d", scheme, ip, port)
+		}
+	} else if host == "localhost" || host == "127.0.0.1" {
+		logger.Info("Server started at %s%s", host, suffix)
+		logger.Info("Running %s://localhost:%d", scheme, port)
+	} else {
+		logger.Info("Server started at %s%s", host, suffix)
+		logger.Info("Running %s://%s:%d", scheme, host, port)
+	}
 }
+
 func getAllIPs() []string {
 	// 初始化结果切片，第一个元素固定为127.0.0.1
 	ipList := []string{"localhost"}
@@ -0,0 +1,73 @@
+package FastGo
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// headWriter HEAD 请求的响应写入器：丢弃响应体但统计长度，
+// 处理器没有设置 Content-Length 时在请求结束后补上，使响应头与 GET 一致
+type headWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool // 已写入底层的响应头
+}
+
+func newHeadWriter(w http.ResponseWriter) *headWriter {
+	return &headWriter{ResponseWriter: w}
+}
+
+// WriteHeader 记录状态码，推迟到请求结束或 Flush 时写入；1xx 信息响应直接写入
+func (w *headWriter) WriteHeader(code int) {
+	if code >= 100 && code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write 丢弃响应体，只统计长度
+func (w *headWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.size += int64(len(b))
+	return len(b), nil
+}
+
+// Flush 流式响应无法预知长度，立即写入响应头
+func (w *headWriter) Flush() {
+	w.commit(false)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 访问底层的 ResponseWriter
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish 请求结束时写入响应头
+func (w *headWriter) finish() {
+	w.commit(true)
+}
+
+func (w *headWriter) commit(done bool) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	header := w.ResponseWriter.Header()
+	if done && w.size > 0 && header.Get("Content-Length") == "" && header.Get("Transfer-Encoding") == "" &&
+		w.status != http.StatusNoContent && w.status != http.StatusNotModified {
+		header.Set("Content-Length", strconv.FormatInt(w.size, 10))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
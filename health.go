@@ -0,0 +1,250 @@
+package FastGo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultCheckTimeout 健康检查的默认超时时间
+	defaultCheckTimeout = 5 * time.Second
+
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+)
+
+// HealthCheckFunc 健康检查函数，返回错误表示检查失败，ctx 在检查超时后取消
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheckOption 健康检查选项
+type HealthCheckOption func(*healthCheck)
+
+// CheckTimeout 设置检查的超时时间，默认5秒
+func CheckTimeout(timeout time.Duration) HealthCheckOption {
+	return func(k *healthCheck) {
+		if timeout > 0 {
+			k.timeout = timeout
+		}
+	}
+}
+
+// CheckCacheTTL 设置检查结果的缓存时间，缓存期内的探测直接返回上次结果，避免频繁访问依赖服务
+func CheckCacheTTL(ttl time.Duration) HealthCheckOption {
+	return func(k *healthCheck) {
+		k.cacheTTL = ttl
+	}
+}
+
+// CheckLiveness 将检查同时用于存活探测（/healthz），默认只用于就绪探测（/readyz）
+// 存活检查失败会导致容器被重启，只应检查进程自身的状态
+func CheckLiveness() HealthCheckOption {
+	return func(k *healthCheck) {
+		k.liveness = true
+	}
+}
+
+// PingCheck 返回调用 PingContext 的检查，可直接用于 *sql.DB 等连接池
+func PingCheck(pinger interface{ PingContext(context.Context) error }) HealthCheckFunc {
+	return pinger.PingContext
+}
+
+// DiskSpaceCheck 返回检查 path 所在磁盘可用空间不少于 minFree 字节的检查
+func DiskSpaceCheck(path string, minFree uint64) HealthCheckFunc {
+	return func(ctx context.Context) error {
+		free, err := diskFree(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%s: %d bytes free, need %d", path, free, minFree)
+		}
+		return nil
+	}
+}
+
+// Health 健康检查，提供存活探测 /healthz 和就绪探测 /readyz
+// 服务启动完成（OnReady 钩子执行后）才会就绪，开始优雅关闭时立即返回 503，让负载均衡先摘除流量
+type Health struct {
+	mu     sync.RWMutex
+	checks []*healthCheck
+	ready  atomic.Bool
+
+	shutdownDelay time.Duration // 切换为未就绪后继续接受请求的时间
+}
+
+type healthCheck struct {
+	name     string
+	fn       HealthCheckFunc
+	timeout  time.Duration
+	cacheTTL time.Duration
+	liveness bool
+
+	mu      sync.Mutex
+	result  checkResult
+	checked time.Time
+}
+
+// checkResult 单个检查的结果
+type checkResult struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Health 注册 /healthz 和 /readyz 路由并返回健康检查，多次调用返回同一个实例
+func (h *App) Health() *Health {
+	if h.health != nil {
+		return h.health
+	}
+	h.health = &Health{}
+	h.router.GET(livenessPath, h.health.handleLiveness)
+	h.router.GET(readinessPath, h.health.handleReadiness)
+	// 探测请求不应因过载被拒绝，否则健康的实例会被重启或摘除
+	h.router.priorities.set(livenessPath, PriorityCritical)
+	h.router.priorities.set(readinessPath, PriorityCritical)
+	return h.health
+}
+
+// AddCheck 添加一个命名检查，默认只用于就绪探测
+func (hc *Health) AddCheck(name string, fn HealthCheckFunc, opts ...HealthCheckOption) *Health {
+	k := &healthCheck{name: name, fn: fn, timeout: defaultCheckTimeout}
+	for _, opt := range opts {
+		opt(k)
+	}
+	hc.mu.Lock()
+	hc.checks = append(hc.checks, k)
+	hc.mu.Unlock()
+	return hc
+}
+
+// SetShutdownDelay 设置开始关闭后、停止接受连接前的等待时间
+// 等待期间 /readyz 返回 503 而请求照常处理，使负载均衡有时间通过探测摘除流量
+func (hc *Health) SetShutdownDelay(delay time.Duration) *Health {
+	hc.mu.Lock()
+	hc.shutdownDelay = delay
+	hc.mu.Unlock()
+	return hc
+}
+
+// Ready 返回服务是否处于就绪状态（已启动且未开始关闭）
+func (hc *Health) Ready() bool {
+	return hc.ready.Load()
+}
+
+// handleLiveness 只执行存活检查
+func (hc *Health) handleLiveness(c *Context) {
+	hc.report(c, "", true)
+}
+
+// handleReadiness 未就绪时直接返回 503，否则执行全部检查
+func (hc *Health) handleReadiness(c *Context) {
+	if !hc.ready.Load() {
+		hc.report(c, "unavailable", false)
+		return
+	}
+	hc.report(c, "", false)
+}
+
+// report 并发执行检查并返回 JSON 报告，任一检查失败时返回 503
+// status 不为空时不执行检查，直接以该状态返回 503
+func (hc *Health) report(c *Context, status string, livenessOnly bool) {
+	results := make(map[string]checkResult)
+	if status == "" {
+		status = "ok"
+		hc.mu.RLock()
+		checks := make([]*healthCheck, 0, len(hc.checks))
+		for _, k := range hc.checks {
+			if !livenessOnly || k.liveness {
+				checks = append(checks, k)
+			}
+		}
+		hc.mu.RUnlock()
+
+		var (
+			wg sync.WaitGroup
+			mu sync.Mutex
+		)
+		for _, k := range checks {
+			wg.Add(1)
+			go func(k *healthCheck) {
+				defer wg.Done()
+				result := k.run(c.Request().Context())
+				mu.Lock()
+				results[k.name] = result
+				mu.Unlock()
+			}(k)
+		}
+		wg.Wait()
+		for _, result := range results {
+			if result.Status != "ok" {
+				status = "fail"
+			}
+		}
+	}
+
+	code := http.StatusOK
+	if status != "ok" {
+		code = http.StatusServiceUnavailable
+	}
+	c.SetHeader("Cache-Control", "no-store")
+	c.SendJson(code, JSON{"status": status, "checks": results})
+}
+
+// run 执行检查，缓存期内直接返回上次结果；检查忽略 ctx 时也会在超时后返回失败
+func (k *healthCheck) run(parent context.Context) checkResult {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.cacheTTL > 0 && !k.checked.IsZero() && time.Since(k.checked) < k.cacheTTL {
+		return k.result
+	}
+
+	ctx, cancel := context.WithTimeout(parent, k.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- k.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", k.timeout)
+	}
+
+	k.result = checkResult{Status: "ok", Duration: time.Since(start).String()}
+	if err != nil {
+		k.result.Status = "fail"
+		k.result.Error = err.Error()
+	}
+	k.checked = time.Now()
+	return k.result
+}
+
+// setReady 更新就绪状态，未调用 Health 时忽略
+func (h *App) setReady(ready bool) {
+	if h.health != nil {
+		h.health.ready.Store(ready)
+	}
+}
+
+// markUnready 切换为未就绪，并在 shutdownDelay 内继续提供服务
+func (h *App) markUnready() {
+	if h.health == nil {
+		return
+	}
+	h.health.ready.Store(false)
+	h.health.mu.RLock()
+	delay := h.health.shutdownDelay
+	h.health.mu.RUnlock()
+	if delay > 0 {
+		h.logger.Info("Marked not ready, waiting %s before shutdown", delay)
+		time.Sleep(delay)
+	}
+}
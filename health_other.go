@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd
+
+package FastGo
+
+import "errors"
+
+// diskFree 该平台不支持检查磁盘空间
+func diskFree(path string) (uint64, error) {
+	return 0, errors.New("disk space check is not supported on this platform")
+}
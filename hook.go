@@ -0,0 +1,132 @@
+package FastGo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// defaultHookTimeout 生命周期钩子的默认超时时间
+const defaultHookTimeout = 15 * time.Second
+
+// HookFunc 生命周期钩子，ctx 在钩子超时后取消
+type HookFunc func(ctx context.Context) error
+
+// HookOption 生命周期钩子选项
+type HookOption func(*hook)
+
+// HookName 设置钩子名称，用于日志和错误信息
+func HookName(name string) HookOption {
+	return func(k *hook) {
+		k.name = name
+	}
+}
+
+// HookTimeout 设置钩子的超时时间
+func HookTimeout(timeout time.Duration) HookOption {
+	return func(k *hook) {
+		if timeout > 0 {
+			k.timeout = timeout
+		}
+	}
+}
+
+type hookStage int
+
+const (
+	hookStart    hookStage = iota // 绑定监听地址之前
+	hookReady                     // 所有监听器开始服务之后
+	hookShutdown                  // 开始关闭、排空请求之前
+	hookStop                      // 所有监听器退出之后
+	hookStageCount
+)
+
+var hookStageNames = [hookStageCount]string{"OnStart", "OnReady", "OnShutdown", "OnStop"}
+
+type hook struct {
+	name    string
+	fn      HookFunc
+	timeout time.Duration
+}
+
+// OnStart 注册启动钩子，在绑定监听地址之前按注册顺序执行（如打开数据库连接池、预热缓存）
+// 任一钩子出错时不再启动服务，Serve/Start 返回该错误
+func (h *App) OnStart(fn HookFunc, opts ...HookOption) {
+	h.addHook(hookStart, fn, opts)
+}
+
+// OnReady 注册就绪钩子，在所有监听器开始服务后按注册顺序执行（如注册到服务发现）
+// 任一钩子出错时关闭服务，Serve/Start 返回该错误
+func (h *App) OnReady(fn HookFunc, opts ...HookOption) {
+	h.addHook(hookReady, fn, opts)
+}
+
+// OnShutdown 注册关闭钩子，在停止接受连接、排空请求之前按注册的逆序执行（如从服务发现注销）
+// 钩子出错不会中断关闭流程，所有错误合并后返回
+func (h *App) OnShutdown(fn HookFunc, opts ...HookOption) {
+	h.addHook(hookShutdown, fn, opts)
+}
+
+// OnStop 注册停止钩子，在所有监听器退出后按注册的逆序执行（如刷新异步日志、关闭连接池）
+// 钩子出错不会中断后续钩子，所有错误合并后返回
+func (h *App) OnStop(fn HookFunc, opts ...HookOption) {
+	h.addHook(hookStop, fn, opts)
+}
+
+func (h *App) addHook(stage hookStage, fn HookFunc, opts []HookOption) {
+	k := &hook{
+		name:    "#" + strconv.Itoa(len(h.hooks[stage])+1),
+		fn:      fn,
+		timeout: defaultHookTimeout,
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	h.hooks[stage] = append(h.hooks[stage], k)
+}
+
+// runHooks 执行某一阶段的钩子
+// OnStart/OnReady 按注册顺序执行并在第一个错误处停止；OnShutdown/OnStop 逆序执行全部钩子并合并错误
+func (h *App) runHooks(stage hookStage) error {
+	hooks := h.hooks[stage]
+	if stage == hookStart || stage == hookReady {
+		for _, k := range hooks {
+			if err := k.run(stage); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].run(stage); err != nil {
+			h.logger.Error("%v", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run 在超时时间内执行钩子，钩子忽略 ctx 时也会在超时后返回错误
+func (k *hook) run(stage hookStage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- k.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%s hook %s: %w", hookStageNames[stage], k.name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s hook %s timed out after %s", hookStageNames[stage], k.name, k.timeout)
+	}
+}
@@ -0,0 +1,87 @@
+package FastGo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// ListenHTTP3 在 TLS 监听器的同一端口上通过 QUIC 提供 HTTP/3，并在 TCP 响应中添加 Alt-Svc 头通告
+// 需要与 ListenTLS/ListenTLSConfig/ListenCertStore 一起使用
+func ListenHTTP3() ListenOption {
+	return func(ep *endpoint) {
+		ep.http3 = true
+	}
+}
+
+// ServeHTTP3 在 addr 的 TCP 端口上启动 HTTPS 服务，同时在同一 UDP 端口上提供 HTTP/3，行为同 ServeTLS
+// 两者使用同一个处理器链，一起启动和优雅关闭
+func (h *App) ServeHTTP3(ctx context.Context, addr, certFile, keyFile string) error {
+	return h.serveEndpoints(ctx, &endpoint{addr: addr, tls: true, certFile: certFile, keyFile: keyFile, http3: true})
+}
+
+// RunHTTP3 启动 HTTPS 和 HTTP/3 服务并阻塞，直到收到 SIGINT/SIGTERM
+func (h *App) RunHTTP3(addr, certFile, keyFile string) {
+	ctx, stop := signalContext(h.logger)
+	defer stop()
+	if err := h.ServeHTTP3(ctx, addr, certFile, keyFile); err != nil {
+		h.logger.Error("Server failed to start (HTTP/3): %v", err)
+	}
+}
+
+// bindHTTP3 在 TCP 监听器的同一端口上创建 UDP 套接字和 HTTP/3 服务器
+// 热重启启动的新进程优先使用父进程传入的 UDP 套接字
+func (h *App) bindHTTP3(ep *endpoint) error {
+	tcpAddr, ok := ep.ln.Addr().(*net.TCPAddr)
+	if !ok {
+		return errors.New("HTTP/3 requires a TCP listener")
+	}
+
+	conn := takeInheritedPacket(ep.addr)
+	if conn == nil {
+		var err error
+		conn, err = net.ListenPacket("udp", net.JoinHostPort(tcpAddr.IP.String(), strconv.Itoa(tcpAddr.Port)))
+		if err != nil {
+			return err
+		}
+	}
+
+	ep.udp = conn
+	ep.h3 = &http3.Server{
+		Handler:        ep.core,
+		TLSConfig:      ep.tlsConfig,
+		QUICConfig:     h.quicConfig,
+		Port:           tcpAddr.Port,
+		IdleTimeout:    ep.core.server.IdleTimeout,
+		MaxHeaderBytes: ep.core.server.MaxHeaderBytes,
+	}
+	ep.core.addHTTP3(tcpAddr.Port, ep.h3)
+	h.logger.Info("HTTP/3 started at udp %s", conn.LocalAddr())
+	return nil
+}
+
+// addHTTP3 记录与 TCP 端口对应的 HTTP/3 服务器，该端口上的 TLS 响应会通告 HTTP/3
+func (s *core) addHTTP3(port int, server *http3.Server) {
+	if s.http3 == nil {
+		s.http3 = make(map[int]*http3.Server)
+	}
+	s.http3[port] = server
+}
+
+// setAltSvc 为 TLS 连接上的请求添加 Alt-Svc 头，通告同端口上的 HTTP/3 服务
+func (s *core) setAltSvc(writer http.ResponseWriter, request *http.Request) {
+	if request.TLS == nil || request.ProtoMajor >= 3 {
+		return
+	}
+	addr, ok := request.Context().Value(http.LocalAddrContextKey).(*net.TCPAddr)
+	if !ok {
+		return
+	}
+	if server := s.http3[addr.Port]; server != nil {
+		_ = server.SetQUICHeaders(writer.Header())
+	}
+}
@@ -0,0 +1,114 @@
+package FastGo
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// loopbackCert 在临时目录中用开发 CA 签发 127.0.0.1 的证书，返回证书路径和信任该 CA 的证书池
+func loopbackCert(t *testing.T) (certFile, keyFile string, roots *x509.CertPool) {
+	t.Helper()
+	dir := t.TempDir()
+	ca, caKey, err := createDevCA(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	if err := createDevLeaf(certFile, keyFile, []string{"127.0.0.1"}, ca, caKey); err != nil {
+		t.Fatal(err)
+	}
+	roots = x509.NewCertPool()
+	roots.AddCert(ca)
+	return certFile, keyFile, roots
+}
+
+// freePort 返回一个当前空闲的 TCP 端口
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestServeHTTP3Loopback(t *testing.T) {
+	certFile, keyFile, roots := loopbackCert(t)
+	port := freePort(t)
+	addr := "127.0.0.1:" + strconv.Itoa(port)
+
+	app := NewFastGo(WithMiddlewares())
+	app.Router().GET("/proto", func(c *Context) {
+		c.SendString(http.StatusOK, c.Request().Proto)
+	})
+	ready := make(chan struct{})
+	app.OnReady(func(ctx context.Context) error {
+		close(ready)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.ServeHTTP3(ctx, addr, certFile, keyFile)
+	}()
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("ServeHTTP3: %v", err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("server not ready")
+	}
+
+	tlsConfig := &tls.Config{RootCAs: roots}
+
+	// TCP 响应通告同端口的 HTTP/3
+	tcpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}, Timeout: 5 * time.Second}
+	defer tcpClient.CloseIdleConnections()
+	resp, err := tcpClient.Get("https://" + addr + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if want := `h3=":` + strconv.Itoa(port) + `"; ma=2592000`; resp.Header.Get("Alt-Svc") != want {
+		t.Errorf("Alt-Svc = %q, want %q", resp.Header.Get("Alt-Svc"), want)
+	}
+
+	// 通过 QUIC 请求同一端口
+	h3 := &http3.Transport{TLSClientConfig: tlsConfig}
+	defer h3.Close()
+	h3Client := &http.Client{Transport: h3, Timeout: 5 * time.Second}
+	resp, err = h3Client.Get("https://" + addr + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "HTTP/3.0" {
+		t.Errorf("HTTP/3 response = %d %q, want 200 %q", resp.StatusCode, body, "HTTP/3.0")
+	}
+	if resp.Header.Get("Alt-Svc") != "" {
+		t.Errorf("HTTP/3 response should not carry Alt-Svc, got %q", resp.Header.Get("Alt-Svc"))
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("shutdown: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -0,0 +1,338 @@
+package FastGo
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Priority 请求优先级，并发达到上限时高优先级的请求先出队，队列满时低优先级的请求先被拒绝
+type Priority int
+
+const (
+	PriorityLow      Priority = iota // 可以最先丢弃的请求，如批量导出
+	PriorityNormal                   // 默认优先级
+	PriorityHigh                     // 核心业务请求
+	PriorityCritical                 // 不受并发限制，如健康检查
+)
+
+const (
+	defaultInitialLimit   = 100
+	defaultMinLimit       = 10
+	defaultMaxLimit       = 1000
+	defaultQueueSize      = 100
+	defaultQueueTimeout   = time.Second
+	defaultRetryAfter     = time.Second
+	defaultLatencyFactor  = 2.0
+	defaultBackoffRatio   = 0.9
+	defaultSampleWindow   = time.Second // 采样窗口长度，每个窗口最多调整一次上限
+	defaultWindowSamples  = 10          // 窗口内最少的请求数，不足时延长窗口
+	defaultBaselineWeight = 0.1         // 窗口延迟计入基准延迟的权重
+	defaultUtilization    = 0.8         // 窗口内并发峰值达到上限的该比例时才降低上限
+)
+
+// ConcurrencyLimiter 自适应并发限制器（AIMD）
+// 按采样窗口统计请求的平均延迟，基准延迟为各窗口平均延迟的指数加权平均；
+// 窗口延迟超过基准延迟 latencyFactor 倍且并发接近上限时上限按比例下降，否则在并发较高时缓慢增长。
+// 达到上限的请求进入有界等待队列，队列满或等待超时时立即返回 503 和 Retry-After
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	limit    float64
+	minLimit float64
+	maxLimit float64
+	inflight int
+
+	queue        [PriorityCritical][]*waiter // 按优先级排队，同一优先级先进先出
+	queued       int
+	queueSize    int
+	queueTimeout time.Duration
+	retryAfter   time.Duration
+
+	latencyFactor float64
+	baseline      float64 // 基准延迟（纳秒），各窗口平均延迟的指数加权平均
+
+	windowStart   time.Time     // 当前采样窗口的开始时间
+	windowSamples int           // 当前窗口完成的请求数
+	windowRTT     time.Duration // 当前窗口的延迟总和
+	windowPeak    int           // 当前窗口的并发峰值
+
+	rejected atomic.Int64
+}
+
+// LimiterStats 并发限制器的状态，用于监控指标
+type LimiterStats struct {
+	Limit    int   `json:"limit"`    // 当前并发上限
+	InFlight int   `json:"inflight"` // 正在处理的请求数
+	Queued   int   `json:"queued"`   // 排队等待的请求数
+	Rejected int64 `json:"rejected"` // 累计拒绝的请求数
+}
+
+// waiter 排队等待的请求，granted 接收是否获得执行名额
+type waiter struct {
+	priority Priority
+	granted  chan bool
+}
+
+// NewConcurrencyLimiter 创建自适应并发限制器，默认初始上限100、范围10~1000、队列长度100
+func NewConcurrencyLimiter() *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		limit:         defaultInitialLimit,
+		minLimit:      defaultMinLimit,
+		maxLimit:      defaultMaxLimit,
+		queueSize:     defaultQueueSize,
+		queueTimeout:  defaultQueueTimeout,
+		retryAfter:    defaultRetryAfter,
+		latencyFactor: defaultLatencyFactor,
+	}
+}
+
+// SetLimits 设置初始并发上限和自适应调整的范围
+func (l *ConcurrencyLimiter) SetLimits(initial, min, max int) *ConcurrencyLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if min > 0 {
+		l.minLimit = float64(min)
+	}
+	if max >= min && max > 0 {
+		l.maxLimit = float64(max)
+	}
+	if initial > 0 {
+		l.limit = clampFloat(float64(initial), l.minLimit, l.maxLimit)
+	}
+	return l
+}
+
+// SetQueue 设置等待队列长度和最长等待时间，size 为 0 时达到上限的请求直接被拒绝
+func (l *ConcurrencyLimiter) SetQueue(size int, timeout time.Duration) *ConcurrencyLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if size >= 0 {
+		l.queueSize = size
+	}
+	if timeout > 0 {
+		l.queueTimeout = timeout
+	}
+	return l
+}
+
+// SetRetryAfter 设置拒绝请求时 Retry-After 响应头的值，默认1秒
+func (l *ConcurrencyLimiter) SetRetryAfter(d time.Duration) *ConcurrencyLimiter {
+	if d > 0 {
+		l.retryAfter = d
+	}
+	return l
+}
+
+// SetLatencyFactor 设置延迟容忍倍数，窗口平均延迟超过基准延迟的该倍数时降低并发上限，默认2
+func (l *ConcurrencyLimiter) SetLatencyFactor(factor float64) *ConcurrencyLimiter {
+	if factor > 1 {
+		l.mu.Lock()
+		l.latencyFactor = factor
+		l.mu.Unlock()
+	}
+	return l
+}
+
+// Stats 返回限制器当前状态
+func (l *ConcurrencyLimiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LimiterStats{
+		Limit:    int(l.limit),
+		InFlight: l.inflight,
+		Queued:   l.queued,
+		Rejected: l.rejected.Load(),
+	}
+}
+
+// acquire 获取执行名额，获得名额时返回请求结束后调用的 release
+// 队列已满、等待超时或请求被取消时返回 false
+func (l *ConcurrencyLimiter) acquire(ctx context.Context, priority Priority) (func(), bool) {
+	l.mu.Lock()
+	priority = clampPriority(priority)
+	if priority >= PriorityCritical || (l.inflight < int(l.limit) && l.queued == 0) {
+		l.inflight++
+		l.mu.Unlock()
+		return l.releaseFunc(priority), true
+	}
+
+	// 队列已满时挤掉优先级更低的等待请求，没有则拒绝当前请求
+	if l.queued >= l.queueSize && !l.evictBelow(priority) {
+		l.mu.Unlock()
+		l.rejected.Add(1)
+		return nil, false
+	}
+	w := &waiter{priority: priority, granted: make(chan bool, 1)}
+	l.queue[priority] = append(l.queue[priority], w)
+	l.queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	select {
+	case ok := <-w.granted:
+		if ok {
+			return l.releaseFunc(priority), true
+		}
+	case <-timer.C:
+		l.cancel(w)
+	case <-ctx.Done():
+		l.cancel(w)
+	}
+	l.rejected.Add(1)
+	return nil, false
+}
+
+// cancel 将超时或取消的请求移出队列；已经获得名额时归还名额
+func (l *ConcurrencyLimiter) cancel(w *waiter) {
+	l.mu.Lock()
+	queue := l.queue[w.priority]
+	for i, q := range queue {
+		if q == w {
+			l.queue[w.priority] = append(queue[:i], queue[i+1:]...)
+			l.queued--
+			l.mu.Unlock()
+			return
+		}
+	}
+	l.mu.Unlock()
+
+	// 出队和超时同时发生
+	if <-w.granted {
+		l.mu.Lock()
+		l.inflight--
+		l.dispatch()
+		l.mu.Unlock()
+	}
+}
+
+// evictBelow 拒绝一个优先级低于 priority 的最新排队请求，腾出队列位置
+func (l *ConcurrencyLimiter) evictBelow(priority Priority) bool {
+	for p := PriorityLow; p < priority; p++ {
+		queue := l.queue[p]
+		if len(queue) == 0 {
+			continue
+		}
+		w := queue[len(queue)-1]
+		l.queue[p] = queue[:len(queue)-1]
+		l.queued--
+		w.granted <- false
+		return true
+	}
+	return false
+}
+
+// dispatch 在上限内按优先级从高到低唤醒排队的请求，调用时需持有锁
+func (l *ConcurrencyLimiter) dispatch() {
+	for l.queued > 0 && l.inflight < int(l.limit) {
+		for p := PriorityCritical - 1; p >= PriorityLow; p-- {
+			if len(l.queue[p]) == 0 {
+				continue
+			}
+			w := l.queue[p][0]
+			l.queue[p] = l.queue[p][1:]
+			l.queued--
+			l.inflight++
+			w.granted <- true
+			break
+		}
+	}
+}
+
+// releaseFunc 返回归还名额并根据延迟调整上限的函数，不受限制的请求不参与调整
+func (l *ConcurrencyLimiter) releaseFunc(priority Priority) func() {
+	start := time.Now()
+	return func() {
+		rtt := time.Since(start)
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if priority < PriorityCritical {
+			l.update(time.Now(), rtt)
+		}
+		l.inflight--
+		l.dispatch()
+	}
+}
+
+// update 记录一次请求的延迟，采样窗口结束时根据窗口的平均延迟调整并发上限，调用时需持有锁
+// 每个窗口最多调整一次，空闲时不会因为个别慢请求降低上限
+func (l *ConcurrencyLimiter) update(now time.Time, rtt time.Duration) {
+	if l.windowStart.IsZero() {
+		l.windowStart = now
+	}
+	l.windowSamples++
+	l.windowRTT += rtt
+	if l.inflight > l.windowPeak {
+		l.windowPeak = l.inflight
+	}
+	if now.Sub(l.windowStart) < defaultSampleWindow || l.windowSamples < defaultWindowSamples {
+		return
+	}
+
+	avg := float64(l.windowRTT) / float64(l.windowSamples)
+	switch {
+	case l.baseline == 0:
+		// 第一个窗口只建立基准
+	case avg > l.baseline*l.latencyFactor && float64(l.windowPeak) >= l.limit*defaultUtilization:
+		l.limit = clampFloat(l.limit*defaultBackoffRatio, l.minLimit, l.maxLimit)
+	case avg <= l.baseline*l.latencyFactor && l.windowPeak*2 >= int(l.limit):
+		// 只有并发接近上限时才增长，避免空闲时上限无限增大；增幅与逐个请求加 1/limit 相同
+		l.limit = clampFloat(l.limit+float64(l.windowSamples)/l.limit, l.minLimit, l.maxLimit)
+	}
+	if l.baseline == 0 {
+		l.baseline = avg
+	} else {
+		l.baseline += (avg - l.baseline) * defaultBaselineWeight
+	}
+
+	l.windowStart = now
+	l.windowSamples = 0
+	l.windowRTT = 0
+	l.windowPeak = l.inflight
+}
+
+// reject 返回 503 并设置 Retry-After
+func (l *ConcurrencyLimiter) reject(writer http.ResponseWriter) {
+	seconds := int((l.retryAfter + time.Second - 1) / time.Second)
+	writer.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(writer, "503 Service Unavailable", http.StatusServiceUnavailable)
+}
+
+// clampPriority 将优先级限制在 PriorityLow 到 PriorityCritical 之间
+func clampPriority(priority Priority) Priority {
+	if priority < PriorityLow {
+		return PriorityLow
+	}
+	if priority > PriorityCritical {
+		return PriorityCritical
+	}
+	return priority
+}
+
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// SetPriority 设置路由组下所有请求在并发限制中的优先级，前缀可以包含 :param 段，嵌套分组中前缀最具体的设置生效
+// 超出 PriorityLow~PriorityCritical 的值按最近的优先级处理
+func (group *RouteGroup) SetPriority(priority Priority) *RouteGroup {
+	group.router.priorities.set(group.getFullPath(""), clampPriority(priority))
+	return group
+}
+
+// priorityOf 返回请求路径的优先级，未设置时为 PriorityNormal
+func (r *Router) priorityOf(path string) Priority {
+	if p, ok := r.priorities.lookup(path); ok {
+		return clampPriority(p)
+	}
+	return PriorityNormal
+}
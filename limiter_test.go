@@ -0,0 +1,90 @@
+package FastGo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// 空闲时快慢交替的请求不应降低上限
+func TestLimiterIdleMixedLatency(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	now := time.Now()
+	for i := 0; i < 40; i++ {
+		rtt := time.Millisecond
+		if i%2 == 1 {
+			rtt = 5 * time.Millisecond
+		}
+		now = now.Add(100 * time.Millisecond)
+		l.inflight = 1
+		l.update(now, rtt)
+	}
+	if got := l.Stats().Limit; got != defaultInitialLimit {
+		t.Errorf("limit = %d, want %d", got, defaultInitialLimit)
+	}
+}
+
+// 并发打满且延迟升高时每个采样窗口只降低一次
+func TestLimiterBackoffOncePerWindow(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	now := time.Now()
+	l.windowStart = now
+	run := func(rtt time.Duration, windows int) {
+		for w := 0; w < windows; w++ {
+			for i := 0; i < 100; i++ {
+				now = now.Add(defaultSampleWindow / 100)
+				l.inflight = int(l.limit)
+				l.update(now, rtt)
+			}
+		}
+	}
+
+	run(time.Millisecond, 2)
+	before := l.limit
+	run(10*time.Millisecond, 1)
+	if want := before * defaultBackoffRatio; l.limit < want-1e-9 || l.limit > want+1e-9 {
+		t.Errorf("limit = %.2f after one slow window, want %.2f", l.limit, want)
+	}
+}
+
+func TestLimiterPriorityOutOfRange(t *testing.T) {
+	l := NewConcurrencyLimiter().SetLimits(10, 10, 10).SetQueue(1, 10*time.Millisecond)
+	l.inflight = 10
+	if _, ok := l.acquire(context.Background(), Priority(-1)); ok {
+		t.Error("acquire succeeded above the limit")
+	}
+	if release, ok := l.acquire(context.Background(), Priority(99)); !ok {
+		t.Error("priority above PriorityCritical should not be limited")
+	} else {
+		release()
+	}
+
+	r := NewRouter()
+	r.Group("/low").SetPriority(Priority(-5))
+	if got := r.priorityOf("/low/x"); got != PriorityLow {
+		t.Errorf("priorityOf = %d, want PriorityLow", got)
+	}
+}
+
+func TestPriorityParamGroup(t *testing.T) {
+	r := NewRouter()
+	tenants := r.Group("/tenants/:tid")
+	tenants.SetPriority(PriorityCritical)
+	tenants.Group("/export").SetPriority(PriorityLow)
+
+	tests := []struct {
+		path string
+		want Priority
+	}{
+		{"/tenants/42/orders", PriorityCritical},
+		{"/tenants/42", PriorityCritical},
+		{"/tenants/42/export/all", PriorityLow},
+		{"/tenants", PriorityNormal},
+		{"/other", PriorityNormal},
+	}
+	for _, tt := range tests {
+		if got := r.priorityOf(tt.path); got != tt.want {
+			t.Errorf("priorityOf(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+package FastGo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+const (
+	unixPrefix    = "unix:"    // Unix 域套接字地址前缀，如 unix:/run/app.sock
+	systemdPrefix = "systemd:" // systemd 套接字激活地址前缀，如 systemd: 或 systemd:http
+
+	// defaultSocketMode Unix 套接字文件默认权限，允许同组用户（如 nginx）连接
+	defaultSocketMode os.FileMode = 0660
+
+	// sdListenFdsStart systemd 传入的第一个文件描述符
+	sdListenFdsStart = 3
+)
+
+// endpoint 描述一个监听器及其处理请求的方式
+type endpoint struct {
+	addr      string       // 监听地址，ln 不为空时忽略
+	ln        net.Listener // 已创建的监听器
+	tls       bool
+	certFile  string
+	keyFile   string
+	tlsConfig *tls.Config // 指定时忽略 certFile/keyFile
+
+	clientAuth ClientAuthMode // 客户端证书认证模式
+	clientCAs  *x509.CertPool // 校验客户端证书的 CA
+	router     *Router        // 独立路由器，为空时使用 App 的路由器
+	redirect   int            // 大于 0 时仅将请求重定向到该 HTTPS 端口
+	core       *core          // 启动时由 App.bind 设置
+
+	limiter *ConnLimiter // 连接限制，为空时不限制
+
+	http3 bool           // 是否在同一端口上提供 HTTP/3
+	udp   net.PacketConn // HTTP/3 使用的 UDP 套接字
+	h3    *http3.Server  // HTTP/3 服务器，启动时由 App.bindHTTP3 设置
+}
+
+// ListenOption 监听器选项，用于 App.Listen
+type ListenOption func(*endpoint)
+
+// ListenTLS 使用证书文件在该监听器上提供 HTTPS 服务，路径都为空时使用自动生成的开发证书
+func ListenTLS(certFile, keyFile string) ListenOption {
+	return func(ep *endpoint) {
+		ep.tls = true
+		ep.certFile = certFile
+		ep.keyFile = keyFile
+	}
+}
+
+// ListenTLSConfig 使用指定的 TLS 配置提供 HTTPS 服务，如 CertStore.TLSConfig() 实现多证书 SNI
+func ListenTLSConfig(config *tls.Config) ListenOption {
+	return func(ep *endpoint) {
+		ep.tls = true
+		ep.tlsConfig = config
+	}
+}
+
+// ListenCertStore 使用证书仓库按 SNI 选择证书提供 HTTPS 服务
+func ListenCertStore(store *CertStore) ListenOption {
+	return func(ep *endpoint) {
+		ep.tls = true
+		ep.tlsConfig = store.TLSConfig()
+	}
+}
+
+// ListenClientAuth 设置该监听器的客户端证书（mTLS）认证模式
+// ClientAuthVerifyIfGiven/ClientAuthVerify 模式使用 clientCAs 校验客户端证书
+func ListenClientAuth(mode ClientAuthMode, clientCAs *x509.CertPool) ListenOption {
+	return func(ep *endpoint) {
+		ep.clientAuth = mode
+		ep.clientCAs = clientCAs
+	}
+}
+
+// ListenConnLimiter 为该监听器设置连接限制，覆盖 WithConnLimiter 的全局设置
+func ListenConnLimiter(limiter *ConnLimiter) ListenOption {
+	return func(ep *endpoint) {
+		ep.limiter = limiter
+	}
+}
+
+// ListenRouter 该监听器使用独立的路由器（如内部管理端口），全局中间件仍然生效
+func ListenRouter(router *Router) ListenOption {
+	return func(ep *endpoint) {
+		ep.router = router
+	}
+}
+
+// ListenRedirectHTTPS 该监听器不处理路由，只将所有请求重定向到 port 端口的 HTTPS 地址
+func ListenRedirectHTTPS(port int) ListenOption {
+	return func(ep *endpoint) {
+		if port <= 0 {
+			port = 443
+		}
+		ep.redirect = port
+	}
+}
+
+// serve 在已绑定的监听器上提供服务，连接限制和 TLS 在此处包装以保留原始监听器供热重启使用
+// 开启 HTTP/3 时同时在 UDP 套接字上提供服务，任一方退出即返回
+func (ep *endpoint) serve() error {
+	ln := ep.ln
+	if ep.limiter != nil {
+		ln = ep.limiter.Wrap(ln)
+	}
+	if !ep.tls {
+		return ep.core.serve(ln)
+	}
+	ln = tls.NewListener(ln, ep.tlsConfig)
+	if ep.h3 == nil {
+		return ep.core.serve(ln)
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- ep.core.serve(ln)
+	}()
+	go func() {
+		errCh <- ep.h3.Serve(ep.udp)
+	}()
+	return <-errCh
+}
+
+// close 关闭监听器和 HTTP/3 的 UDP 套接字
+func (ep *endpoint) close() {
+	_ = ep.ln.Close()
+	if ep.udp != nil {
+		_ = ep.udp.Close()
+	}
+}
+
+// buildTLSConfig 生成该监听器的 TLS 配置
+// 未指定 tlsConfig 时以服务器的 TLSConfig 为基础，从证书文件创建可热加载的 CertStore；
+// 证书和私钥路径都为空时使用 devCertDir 中由本地 CA 签发的开发证书
+func (ep *endpoint) buildTLSConfig(devCertDir string, logger Logger) error {
+	var config *tls.Config
+	if ep.tlsConfig != nil {
+		config = ep.tlsConfig.Clone()
+	} else {
+		store := NewCertStore().SetLogger(logger)
+		if ep.certFile == "" && ep.keyFile == "" {
+			cert, err := devCertificate(logger, devCertDir, ep.host())
+			if err != nil {
+				return err
+			}
+			if err := store.AddCertificate(cert); err != nil {
+				return err
+			}
+		} else if err := store.AddFile(ep.certFile, ep.keyFile); err != nil {
+			return err
+		}
+		if ep.core.server.TLSConfig != nil {
+			config = ep.core.server.TLSConfig.Clone()
+		} else {
+			config = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		config.GetCertificate = store.GetCertificate
+	}
+
+	if ep.clientAuth != ClientAuthNone {
+		if (ep.clientAuth == ClientAuthVerify || ep.clientAuth == ClientAuthVerifyIfGiven) && ep.clientCAs == nil {
+			return errors.New("client certificate verification requires a CA pool")
+		}
+		config.ClientAuth = ep.clientAuth.tlsClientAuth()
+		config.ClientCAs = ep.clientCAs
+	}
+
+	// 与 http.Server.ServeTLS 一致，默认通过 ALPN 协商 HTTP/2
+	if len(config.NextProtos) == 0 {
+		protocols := ep.core.server.Protocols
+		if protocols == nil || protocols.HTTP2() {
+			config.NextProtos = append(config.NextProtos, "h2")
+		}
+		config.NextProtos = append(config.NextProtos, "http/1.1")
+	}
+	ep.tlsConfig = config
+	return nil
+}
+
+// host 返回监听地址中具体的主机名，通配地址和非 TCP 地址返回空字符串
+func (ep *endpoint) host() string {
+	host, _, err := net.SplitHostPort(ep.addr)
+	if err != nil || host == "" || host == "0.0.0.0" || host == "::" {
+		return ""
+	}
+	return host
+}
+
+// endpointCores 返回监听器使用的 core，共享的 core 只出现一次
+func endpointCores(endpoints []*endpoint) []*core {
+	cores := make([]*core, 0, len(endpoints))
+	seen := make(map[*core]struct{}, len(endpoints))
+	for _, ep := range endpoints {
+		if _, ok := seen[ep.core]; ok {
+			continue
+		}
+		seen[ep.core] = struct{}{}
+		cores = append(cores, ep.core)
+	}
+	return cores
+}
+
+// redirectHTTPS 返回将请求重定向到 HTTPS 的处理器
+// GET/HEAD 使用 301，其他方法使用 308 以保留请求方法和请求体
+func redirectHTTPS(port int) HandlerFunc {
+	return func(c *Context) {
+		host := c.Host()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			// 不带端口的 IPv6 地址 [::1]
+			host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		}
+		if port != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(port))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		code := http.StatusPermanentRedirect
+		if c.IsGet() || c.IsHead() {
+			code = http.StatusMovedPermanently
+		}
+		c.Redirect(code, "https://"+host+c.FullPath())
+	}
+}
+
+// listen 按地址类型创建监听器并打印访问地址
+func (h *App) listen(addr string, https bool) (net.Listener, error) {
+	scheme := "http"
+	if https {
+		scheme = "https"
+	}
+
+	// 热重启启动的新进程直接使用父进程传入的监听器
+	if ln := takeInherited(addr); ln != nil {
+		h.logger.Info("Server resumed at %s (%s, inherited)", addr, scheme)
+		return ln, nil
+	}
+
+	switch {
+	case strings.HasPrefix(addr, unixPrefix):
+		path := strings.TrimPrefix(addr, unixPrefix)
+		ln, err := listenUnix(path, h.socketMode)
+		if err != nil {
+			return nil, err
+		}
+		h.logger.Info("Server started at %s (%s)", addr, scheme)
+		return ln, nil
+	case strings.HasPrefix(addr, systemdPrefix):
+		name := strings.TrimPrefix(addr, systemdPrefix)
+		ln, err := systemdListener(name)
+		if err != nil {
+			return nil, err
+		}
+		h.logger.Info("Server started on systemd socket %s (%s)", ln.Addr(), scheme)
+		return ln, nil
+	}
+
+	host, port, err := parseAddress(addr, https)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	printAddress(h.logger, scheme, host, port)
+	return ln, nil
+}
+
+// listenUnix 创建 Unix 域套接字监听器
+// 已存在的套接字文件若无进程监听则视为残留并删除；监听器关闭时自动删除套接字文件
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("empty unix socket path")
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("chmod unix socket %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// removeStaleSocket 删除上次进程异常退出后残留的套接字文件
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a unix socket", path)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("unix socket %s is already in use", path)
+	}
+	return os.Remove(path)
+}
+
+// inheritedListener systemd 传入的监听器
+type inheritedListener struct {
+	name string
+	ln   net.Listener
+	used bool
+}
+
+var (
+	systemdOnce      sync.Once
+	systemdMu        sync.Mutex
+	systemdListeners []*inheritedListener
+	systemdErr       error
+)
+
+// systemdListener 返回 systemd 传入的监听器，name 为空时返回第一个未使用的监听器
+// name 对应 socket 单元的 FileDescriptorName，每个监听器只能被取用一次
+func systemdListener(name string) (net.Listener, error) {
+	systemdOnce.Do(func() {
+		systemdListeners, systemdErr = inheritSystemdListeners()
+	})
+	if systemdErr != nil {
+		return nil, systemdErr
+	}
+
+	systemdMu.Lock()
+	defer systemdMu.Unlock()
+	for _, l := range systemdListeners {
+		if l.used || (name != "" && l.name != name) {
+			continue
+		}
+		l.used = true
+		return l.ln, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no unused systemd socket (LISTEN_FDS=%d)", len(systemdListeners))
+	}
+	return nil, fmt.Errorf("no unused systemd socket named %q", name)
+}
+
+// inheritSystemdListeners 按 sd_listen_fds 协议解析 LISTEN_PID/LISTEN_FDS/LISTEN_FDNAMES
+// 解析后清除这些环境变量，避免被子进程重复继承
+func inheritSystemdListeners() ([]*inheritedListener, error) {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	if pid := os.Getenv("LISTEN_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return nil, fmt.Errorf("LISTEN_PID %s does not match current process %d", pid, os.Getpid())
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, errors.New("no sockets passed by systemd (LISTEN_FDS not set)")
+	}
+
+	var names []string
+	if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+
+	listeners := make([]*inheritedListener, 0, count)
+	for i := 0; i < count; i++ {
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(sdListenFdsStart+i), name)
+		ln, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("systemd fd %d: %w", sdListenFdsStart+i, err)
+		}
+		listeners = append(listeners, &inheritedListener{name: name, ln: ln})
+	}
+	return listeners, nil
+}
@@ -0,0 +1,90 @@
+package FastGo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// Logger 框架使用的日志接口，参数为 fmt.Sprintf 风格的格式化字符串
+// 可以通过适配器接入 slog、zap 等日志库
+type Logger interface {
+	Debug(format string, args ...any)
+	Info(format string, args ...any)
+	Warn(format string, args ...any)
+	Error(format string, args ...any)
+}
+
+// defaultLogger 框架默认日志器，写入 slog.Default()
+var defaultLogger = NewSlogLogger(nil, "module", "FastGo")
+
+// SetDefaultLogger 设置包级默认日志器，影响之后创建的 App 和 CertStore
+func SetDefaultLogger(logger Logger) {
+	if logger != nil {
+		defaultLogger = logger
+	}
+}
+
+// slogLogger 基于 log/slog 的 Logger 实现
+type slogLogger struct {
+	logger *slog.Logger // 为空时使用调用时的 slog.Default()
+	attrs  []any
+}
+
+// NewSlogLogger 创建基于 slog 的日志器，attrs 作为每条日志的附加字段
+// logger 为空时使用 slog.Default()，调用 slog.SetDefault 后同样生效
+func NewSlogLogger(logger *slog.Logger, attrs ...any) Logger {
+	return &slogLogger{logger: logger, attrs: attrs}
+}
+
+// moduleLogger 返回 module 字段替换为 module 的 slog 日志器，其他 Logger 实现原样返回
+func moduleLogger(logger Logger, module string) Logger {
+	l, ok := logger.(*slogLogger)
+	if !ok {
+		return logger
+	}
+	attrs := make([]any, 0, len(l.attrs)+2)
+	for i := 0; i+1 < len(l.attrs); i += 2 {
+		if l.attrs[i] != "module" {
+			attrs = append(attrs, l.attrs[i], l.attrs[i+1])
+		}
+	}
+	attrs = append(attrs, "module", module)
+	return &slogLogger{logger: l.logger, attrs: attrs}
+}
+
+func (l *slogLogger) Debug(format string, args ...any) {
+	l.log(slog.LevelDebug, format, args)
+}
+
+func (l *slogLogger) Info(format string, args ...any) {
+	l.log(slog.LevelInfo, format, args)
+}
+
+func (l *slogLogger) Warn(format string, args ...any) {
+	l.log(slog.LevelWarn, format, args)
+}
+
+func (l *slogLogger) Error(format string, args ...any) {
+	l.log(slog.LevelError, format, args)
+}
+
+// log 级别未启用时不格式化消息
+func (l *slogLogger) log(level slog.Level, format string, args []any) {
+	logger := l.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	// 跳过 runtime.Callers、log 和 Info 等方法，记录调用方的位置
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, args...), pcs[0])
+	record.Add(l.attrs...)
+	_ = logger.Handler().Handle(ctx, record)
+}
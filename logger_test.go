@@ -0,0 +1,60 @@
+package FastGo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureLogger 记录日志内容的 Logger
+type captureLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *captureLogger) add(level, format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *captureLogger) Debug(format string, args ...any) { l.add("DEBUG", format, args...) }
+func (l *captureLogger) Info(format string, args ...any)  { l.add("INFO", format, args...) }
+func (l *captureLogger) Warn(format string, args ...any)  { l.add("WARN", format, args...) }
+func (l *captureLogger) Error(format string, args ...any) { l.add("ERROR", format, args...) }
+
+// failingWriter 写入响应体总是失败的 ResponseWriter
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestContextWriteErrorUsesAppLogger(t *testing.T) {
+	logger := &captureLogger{}
+	app := NewFastGo(WithLogger(logger), WithMiddlewares())
+	app.Router().GET("/", func(c *Context) {
+		c.SendString(http.StatusOK, "hello")
+	})
+	app.prepare()
+
+	w := failingWriter{httptest.NewRecorder()}
+	app.core.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	app.core.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	app.core.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	want := []string{
+		"WARN Error writing response: connection reset",
+		"WARN Error writing 404 response: connection reset",
+		"WARN Error writing 405 response: connection reset",
+	}
+	if got := strings.Join(logger.lines, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("logged:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+}
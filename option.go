@@ -0,0 +1,295 @@
+package FastGo
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Option 应用配置选项，用于 NewFastGo
+type Option func(*App)
+
+// serverOption 生成修改 http.Server 的选项
+// 配置会被记录下来，同样应用到 Listen 注册的独立路由器和重定向监听器
+func serverOption(fn func(*http.Server)) Option {
+	return func(a *App) {
+		a.serverOptions = append(a.serverOptions, fn)
+		fn(a.core.server)
+	}
+}
+
+// WithReadTimeout 设置读取整个请求（包括请求体）的超时时间，0 表示不限制
+func WithReadTimeout(timeout time.Duration) Option {
+	return serverOption(func(s *http.Server) {
+		s.ReadTimeout = timeout
+	})
+}
+
+// WithReadHeaderTimeout 设置读取请求头的超时时间，0 表示使用 ReadTimeout
+func WithReadHeaderTimeout(timeout time.Duration) Option {
+	return serverOption(func(s *http.Server) {
+		s.ReadHeaderTimeout = timeout
+	})
+}
+
+// WithWriteTimeout 设置写响应的超时时间，0 表示不限制
+// 大文件下载（如 ServeRange）需要调大或关闭此超时
+func WithWriteTimeout(timeout time.Duration) Option {
+	return serverOption(func(s *http.Server) {
+		s.WriteTimeout = timeout
+	})
+}
+
+// WithIdleTimeout 设置 keep-alive 连接的空闲超时时间
+func WithIdleTimeout(timeout time.Duration) Option {
+	return serverOption(func(s *http.Server) {
+		s.IdleTimeout = timeout
+	})
+}
+
+// WithMaxHeaderBytes 设置请求头的最大字节数
+func WithMaxHeaderBytes(size int) Option {
+	return serverOption(func(s *http.Server) {
+		s.MaxHeaderBytes = size
+	})
+}
+
+// WithKeepAlives 设置是否启用 HTTP keep-alive
+func WithKeepAlives(enabled bool) Option {
+	return serverOption(func(s *http.Server) {
+		s.SetKeepAlivesEnabled(enabled)
+	})
+}
+
+// WithTLSConfig 设置 TLS 配置，供 ServeTLS/RunTLS 使用
+func WithTLSConfig(config *tls.Config) Option {
+	return serverOption(func(s *http.Server) {
+		s.TLSConfig = config
+	})
+}
+
+// WithProtocols 设置服务器支持的协议（HTTP/1、HTTP/2 等）
+func WithProtocols(protocols *http.Protocols) Option {
+	return serverOption(func(s *http.Server) {
+		s.Protocols = protocols
+	})
+}
+
+// WithDisableGeneralOptionsHandler 设置是否关闭 net/http 对 "OPTIONS *" 的默认处理
+func WithDisableGeneralOptionsHandler(disable bool) Option {
+	return serverOption(func(s *http.Server) {
+		s.DisableGeneralOptionsHandler = disable
+	})
+}
+
+// WithConnState 设置连接状态变化回调
+func WithConnState(fn func(net.Conn, http.ConnState)) Option {
+	return serverOption(func(s *http.Server) {
+		s.ConnState = fn
+	})
+}
+
+// WithErrorLog 设置 http.Server 内部错误（如 TLS 握手失败）的日志器
+func WithErrorLog(logger *log.Logger) Option {
+	return serverOption(func(s *http.Server) {
+		s.ErrorLog = logger
+	})
+}
+
+// WithBaseContext 设置每个监听器上请求的根上下文
+func WithBaseContext(fn func(net.Listener) context.Context) Option {
+	return serverOption(func(s *http.Server) {
+		s.BaseContext = fn
+	})
+}
+
+// WithConnContext 设置为每个新连接派生上下文的函数
+func WithConnContext(fn func(ctx context.Context, c net.Conn) context.Context) Option {
+	return serverOption(func(s *http.Server) {
+		s.ConnContext = fn
+	})
+}
+
+// WithMiddlewares 替换默认中间件（默认为日志中间件），不传参数表示不使用任何默认中间件
+func WithMiddlewares(middlewares ...Engine) Option {
+	return func(a *App) {
+		a.middlewares = append(make([]Engine, 0, len(middlewares)), middlewares...)
+	}
+}
+
+// WithLogger 设置框架日志器，默认写入 slog.Default()
+func WithLogger(logger Logger) Option {
+	return func(a *App) {
+		a.SetLogger(logger)
+	}
+}
+
+// WithConnLimiter 为所有监听器设置连接限制，可通过 ListenConnLimiter 为单个监听器单独设置
+func WithConnLimiter(limiter *ConnLimiter) Option {
+	return func(a *App) {
+		a.connLimiter = limiter
+	}
+}
+
+// WithConcurrencyLimiter 限制同时处理的请求数，上限根据请求延迟自适应调整
+// 路由组可通过 SetPriority 设置优先级，健康检查路由不受限制
+func WithConcurrencyLimiter(limiter *ConcurrencyLimiter) Option {
+	return func(a *App) {
+		a.concurrency = limiter
+	}
+}
+
+// WithRecovery 设置处理器 panic 后的回调，默认记录堆栈并返回 500
+func WithRecovery(fn RecoveryFunc) Option {
+	return func(a *App) {
+		a.recoveryFunc = fn
+	}
+}
+
+// WithErrorHandler 设置请求错误的统一处理，见 App.SetErrorHandler
+func WithErrorHandler(fn ErrorHandlerFunc) Option {
+	return func(a *App) {
+		a.errorHandler = fn
+	}
+}
+
+// WithUnixSocketMode 设置 unix: 地址创建的套接字文件权限，默认 0660
+func WithUnixSocketMode(mode os.FileMode) Option {
+	return func(a *App) {
+		a.socketMode = mode
+	}
+}
+
+// WithDevCertDir 设置开发证书（RunTLS 未指定证书文件时自动生成）的缓存目录
+// 默认为用户缓存目录下的 FastGo/devcert
+func WithDevCertDir(dir string) Option {
+	return func(a *App) {
+		a.devCertDir = dir
+	}
+}
+
+// WithHotRestart 开启热重启：收到 SIGHUP/SIGUSR2 时以相同参数启动新的可执行文件，
+// 把 Listen/Serve 按地址创建的监听器交给新进程，新进程就绪后当前进程排空请求并退出。
+// readyTimeout 为等待新进程就绪的时间，0 表示使用默认的30秒
+func WithHotRestart(readyTimeout time.Duration) Option {
+	return func(a *App) {
+		a.hotRestart = true
+		if readyTimeout > 0 {
+			a.upgradeTimeout = readyTimeout
+		}
+	}
+}
+
+// WithShutdownTimeout 设置优雅关闭的最长等待时间
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(a *App) {
+		a.SetShutdownTimeout(timeout)
+	}
+}
+
+// WithACMEDirectory 设置 ACME 目录地址，默认使用 Let's Encrypt 生产环境
+// 测试时可指向 Let's Encrypt staging 或本地 Pebble（如 https://localhost:14000/dir）
+func WithACMEDirectory(url string) Option {
+	return func(a *App) {
+		a.acme.directoryURL = url
+	}
+}
+
+// WithACMEEmail 设置 ACME 账户的联系邮箱，用于接收证书过期提醒
+func WithACMEEmail(email string) Option {
+	return func(a *App) {
+		a.acme.email = email
+	}
+}
+
+// WithACMECache 设置 ACME 证书缓存，默认保存在用户缓存目录下的 FastGo/acme
+func WithACMECache(cache CertCache) Option {
+	return func(a *App) {
+		a.acme.cache = cache
+	}
+}
+
+// WithACMEHTTPClient 设置访问 ACME 服务器的 HTTP 客户端，如信任 Pebble 的测试 CA
+func WithACMEHTTPClient(client *http.Client) Option {
+	return func(a *App) {
+		a.acme.httpClient = client
+	}
+}
+
+// WithAutoTLSAddrs 设置 RunAutoTLS 的 HTTPS 和 HTTP 监听地址，默认为 :443 和 :80
+func WithAutoTLSAddrs(httpsAddr, httpAddr string) Option {
+	return func(a *App) {
+		a.acme.httpsAddr = httpsAddr
+		a.acme.httpAddr = httpAddr
+	}
+}
+
+// WithH2C 开启明文 HTTP/2（h2c），同时支持直接发送连接前言（prior knowledge）和 HTTP/1.1 Upgrade 两种方式
+// 适用于在 TLS 终止代理或服务网格之后提供 HTTP/2；TLS 监听器上的 HTTP/2 不受影响
+func WithH2C() Option {
+	return serverOption(func(s *http.Server) {
+		h2s := &http2.Server{}
+		// ConfigureServer 为 h2c 连接注册关闭钩子，优雅关闭时发送 GOAWAY；
+		// TLS 上的 HTTP/2 仍由 net/http 处理，因此保留原有的 TLS 配置
+		tlsConfig, nextProto := s.TLSConfig, s.TLSNextProto
+		s.TLSConfig = nil
+		_ = http2.ConfigureServer(s, h2s) // 仅在 TLSConfig 的加密套件不兼容时出错
+		s.TLSConfig, s.TLSNextProto = tlsConfig, nextProto
+		s.Handler = h2c.NewHandler(s.Handler, h2s)
+	})
+}
+
+// http2Option 生成修改 HTTP/2 配置的选项，同时作用于 TLS 上的 HTTP/2 和 h2c
+func http2Option(fn func(*http.HTTP2Config)) Option {
+	return serverOption(func(s *http.Server) {
+		if s.HTTP2 == nil {
+			s.HTTP2 = &http.HTTP2Config{}
+		}
+		fn(s.HTTP2)
+	})
+}
+
+// WithHTTP2Config 设置完整的 HTTP/2 配置，未设置的字段使用默认值
+func WithHTTP2Config(config *http.HTTP2Config) Option {
+	return http2Option(func(c *http.HTTP2Config) {
+		*c = *config
+	})
+}
+
+// WithHTTP2MaxConcurrentStreams 设置每个 HTTP/2 连接允许的最大并发流数，默认250
+func WithHTTP2MaxConcurrentStreams(n int) Option {
+	return http2Option(func(c *http.HTTP2Config) {
+		c.MaxConcurrentStreams = n
+	})
+}
+
+// WithHTTP2MaxReadFrameSize 设置允许对端发送的最大帧大小（16KB~16MB），默认1MB
+func WithHTTP2MaxReadFrameSize(size int) Option {
+	return http2Option(func(c *http.HTTP2Config) {
+		c.MaxReadFrameSize = size
+	})
+}
+
+// WithHTTP2FlowControl 设置 HTTP/2 流量控制窗口，即每个连接和每个流最多缓冲的未读数据
+// 默认连接窗口1MB、流窗口1MB，高延迟链路上传大请求体时可以调大
+func WithHTTP2FlowControl(connWindow, streamWindow int) Option {
+	return http2Option(func(c *http.HTTP2Config) {
+		c.MaxReceiveBufferPerConnection = connWindow
+		c.MaxReceiveBufferPerStream = streamWindow
+	})
+}
+
+// WithQUICConfig 设置 HTTP/3 监听器的 QUIC 参数（如最大并发流、保活间隔），nil 时使用默认值
+func WithQUICConfig(config *quic.Config) Option {
+	return func(a *App) {
+		a.quicConfig = config
+	}
+}
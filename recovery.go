@@ -0,0 +1,65 @@
+package FastGo
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// RecoveryFunc 处理器 panic 后调用，err 为 *PanicError，可以自行写入响应
+// 未设置时 panic 作为错误交给 ErrorHandler，默认返回 500
+type RecoveryFunc func(c *Context, err error)
+
+// PanicError 处理器 panic 的值和堆栈，panic 的值不是 error 时同样包装为 PanicError
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap 返回 panic 的值本身（值为 error 时），支持 errors.Is/As
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// recovery 捕获路由处理器的 panic 并统一处理请求中的错误
+// 放在中间件之后，使日志等中间件仍能记录最终的响应
+func (h *App) recovery() HandlerFunc {
+	return func(c *Context) {
+		defer func() {
+			if v := recover(); v != nil {
+				h.handlePanic(c, v, true)
+			}
+			h.handleErrors(c)
+		}()
+		c.Next()
+	}
+}
+
+// handlePanic 记录堆栈和请求 ID，useCallback 为 true 时交给 WithRecovery 设置的回调处理，
+// 未设置回调时作为错误交给 ErrorHandler；useCallback 为 false 时直接返回 500
+// http.ErrAbortHandler 是中止响应的约定，继续向上抛出由 net/http 处理
+func (h *App) handlePanic(c *Context, v any, useCallback bool) {
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+	err := &PanicError{Value: v, Stack: debug.Stack()}
+	h.logger.Error("Panic recovered: %v [request_id=%s %s %s]\n%s",
+		v, c.RequestID(), c.Method(), c.Path(), err.Stack)
+
+	c.Abort()
+	switch {
+	case useCallback && h.recoveryFunc != nil:
+		h.recoveryFunc(c, err)
+	case useCallback:
+		c.Error(err)
+	case !c.written:
+		c.SendString(http.StatusInternalServerError, "500 Internal Server Error")
+	}
+}
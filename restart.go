@@ -0,0 +1,234 @@
+package FastGo
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	envInheritAddrs = "FASTGO_INHERIT_ADDRS" // 热重启时传给新进程的监听地址列表，以 ; 分隔
+	envReadyFD      = "FASTGO_READY_FD"      // 新进程就绪后写入的管道描述符
+	udpPrefix       = "udp:"                 // HTTP/3 的 UDP 套接字在地址列表中的前缀
+
+	// defaultUpgradeTimeout 等待新进程就绪的默认时间
+	defaultUpgradeTimeout = 30 * time.Second
+)
+
+var (
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   map[string]net.Listener
+	inheritedPC map[string]net.PacketConn
+)
+
+// takeInherited 返回热重启时父进程传入的同地址监听器，不存在时返回 nil
+func takeInherited(addr string) net.Listener {
+	inheritOnce.Do(loadInherited)
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	ln := inherited[addr]
+	delete(inherited, addr)
+	return ln
+}
+
+// takeInheritedPacket 返回热重启时父进程传入的 HTTP/3 UDP 套接字，不存在时返回 nil
+func takeInheritedPacket(addr string) net.PacketConn {
+	inheritOnce.Do(loadInherited)
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	conn := inheritedPC[addr]
+	delete(inheritedPC, addr)
+	return conn
+}
+
+// loadInherited 解析父进程通过 ExtraFiles 传入的监听器
+func loadInherited() {
+	inherited = make(map[string]net.Listener)
+	inheritedPC = make(map[string]net.PacketConn)
+	value := os.Getenv(envInheritAddrs)
+	_ = os.Unsetenv(envInheritAddrs)
+	if value == "" {
+		return
+	}
+	for i, addr := range strings.Split(value, ";") {
+		f := os.NewFile(uintptr(sdListenFdsStart+i), addr)
+		if strings.HasPrefix(addr, udpPrefix) {
+			conn, err := net.FilePacketConn(f)
+			_ = f.Close()
+			if err != nil {
+				defaultLogger.Error("Inherit udp socket %s failed: %v", addr, err)
+				continue
+			}
+			inheritedPC[strings.TrimPrefix(addr, udpPrefix)] = conn
+			continue
+		}
+		ln, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			defaultLogger.Error("Inherit listener %s failed: %v", addr, err)
+			continue
+		}
+		// 继承的套接字文件由当前进程负责清理
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(true)
+		}
+		inherited[addr] = ln
+	}
+}
+
+// notifyReady 监听器启动后通知父进程新进程已就绪，只在热重启启动的进程中生效
+func notifyReady() {
+	value := os.Getenv(envReadyFD)
+	_ = os.Unsetenv(envReadyFD)
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	_, _ = f.Write([]byte{1})
+	_ = f.Close()
+}
+
+// watchUpgrade 监听热重启信号，新进程就绪后关闭 upgraded 通道
+// 返回的函数用于停止监听
+func (h *App) watchUpgrade(endpoints []*endpoint, upgraded chan<- struct{}) func() {
+	if len(upgradeSignals) == 0 {
+		h.logger.Error("Hot restart is not supported on this platform")
+		return func() {}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, upgradeSignals...)
+	go func() {
+		for {
+			select {
+			case sign := <-sigCh:
+				h.logger.Info("Receive %s Starting new process...", sign)
+				if err := h.upgrade(endpoints); err != nil {
+					h.logger.Error("Hot restart failed: %v", err)
+					continue
+				}
+				h.logger.Info("New process is ready, draining old connections")
+				close(upgraded)
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
+// upgrade 以相同参数启动新的可执行文件并把监听器交给它，新进程就绪后返回 nil
+// 新进程启动失败或超时未就绪时将其终止，当前进程继续提供服务
+func (h *App) upgrade(endpoints []*endpoint) error {
+	files := make([]*os.File, 0, len(endpoints)+1)
+	addrs := make([]string, 0, len(endpoints))
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, ep := range endpoints {
+		// AddListener 注册的监听器没有地址，新进程无法按地址匹配
+		if ep.addr == "" {
+			continue
+		}
+		fl, ok := ep.ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+		f, err := fl.File()
+		if err != nil {
+			return fmt.Errorf("dup listener %s: %w", ep.addr, err)
+		}
+		files = append(files, f)
+		addrs = append(addrs, ep.addr)
+
+		if uc, ok := ep.udp.(*net.UDPConn); ok {
+			f, err := uc.File()
+			if err != nil {
+				return fmt.Errorf("dup udp socket %s: %w", ep.addr, err)
+			}
+			files = append(files, f)
+			addrs = append(addrs, udpPrefix+ep.addr)
+		}
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		_ = readyW.Close()
+		return err
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, readyW)
+	cmd.Env = append(upgradeEnviron(),
+		envInheritAddrs+"="+strings.Join(addrs, ";"),
+		envReadyFD+"="+strconv.Itoa(sdListenFdsStart+len(files)),
+	)
+	err = cmd.Start()
+	_ = readyW.Close()
+	if err != nil {
+		return err
+	}
+
+	// 新进程退出时管道写端关闭，Read 返回 EOF
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := readyR.Read(buf)
+		ready <- err
+	}()
+	select {
+	case err = <-ready:
+	case <-time.After(h.upgradeTimeout):
+		err = errors.New("timed out waiting for new process")
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("new process %d not ready: %w", cmd.Process.Pid, err)
+	}
+
+	// 套接字文件已由新进程接管，关闭旧监听器时不能删除
+	for _, ep := range endpoints {
+		if ul, ok := ep.ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	h.logger.Info("New process %d started", cmd.Process.Pid)
+	return cmd.Process.Release()
+}
+
+// upgradeEnviron 返回去掉热重启内部变量的环境变量
+func upgradeEnviron() []string {
+	env := make([]string, 0, len(os.Environ()))
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envInheritAddrs+"=") || strings.HasPrefix(kv, envReadyFD+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
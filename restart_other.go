@@ -0,0 +1,8 @@
+//go:build windows || plan9
+
+package FastGo
+
+import "os"
+
+// upgradeSignals 该平台不支持通过信号热重启
+var upgradeSignals []os.Signal
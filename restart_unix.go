@@ -0,0 +1,11 @@
+//go:build !windows && !plan9
+
+package FastGo
+
+import (
+	"os"
+	"syscall"
+)
+
+// upgradeSignals 触发热重启的信号
+var upgradeSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
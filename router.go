@@ -1,6 +1,13 @@
 package FastGo
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -14,7 +21,18 @@ const (
 )
 
 type Router struct {
-	route map[string]*routeNode
+	route      map[string]*routeNode
+	priorities prefixList[Priority]    // 路由组在并发限制中的优先级
+	notFound   HandleChain             // 路由器级 404 处理器
+	groupNF    prefixList[HandleChain] // 路由组级 404 处理器
+
+	methodNotAllowed HandleChain             // 路由器级 405 处理器
+	groupMNA         prefixList[HandleChain] // 路由组级 405 处理器
+
+	emptyCatchAll bool // 通配符是否匹配空的剩余路径
+
+	panicOnConflict bool    // 路由冲突时 panic，否则记录到 errs
+	errs            []error // 注册时记录的冲突
 }
 
 // RouteGroup 表示路由组
@@ -28,7 +46,8 @@ type RouteGroup struct {
 // NewRouter 创建路由
 func NewRouter() *Router {
 	return &Router{
-		route: make(map[string]*routeNode),
+		route:           make(map[string]*routeNode),
+		panicOnConflict: true,
 	}
 }
 
@@ -147,11 +166,22 @@ func (group *RouteGroup) collectHandlersFromRoot(handlers *[]HandlerFunc) {
 	*handlers = append(*handlers, group.handlers...)
 }
 
+// SetEmptyCatchAll 设置通配符是否匹配空的剩余路径，开启后 /static/*filepath 也匹配 /static 和 /static/，
+// filepath 为空字符串；默认不匹配
+func (r *Router) SetEmptyCatchAll(enable bool) *Router {
+	r.emptyCatchAll = enable
+	for _, route := range r.route {
+		route.emptyCatchAll = enable
+	}
+	return r
+}
+
 // getRoute 获取路由
 func (r *Router) getRoute(method string) *routeNode {
 	route, ok := r.route[method]
 	if !ok {
 		route = (&routeNode{}).NewTire()
+		route.emptyCatchAll = r.emptyCatchAll
 	}
 	r.route[method] = route
 	return route
@@ -159,33 +189,123 @@ func (r *Router) getRoute(method string) *routeNode {
 
 // addRoute 添加路由
 func (r *Router) addRoute(path, method string, handlers HandleChain) {
-	route := r.getRoute(method)
-	route.Insert(path, handlers)
+	r.insert(&routeInfo{method: method, pattern: path, source: callerSource()}, handlers)
+}
+
+// insert 插入路由，冲突时 panic 或记录错误
+func (r *Router) insert(info *routeInfo, handlers HandleChain) {
+	if err := r.getRoute(info.method).Insert(info.pattern, handlers, info); err != nil {
+		if r.panicOnConflict {
+			panic(err)
+		}
+		r.errs = append(r.errs, err)
+	}
+}
+
+// SetPanicOnConflict 设置路由重复、参数名冲突或通配符位置错误时是否 panic，默认 panic
+// 关闭后冲突的路由不会注册，错误通过 Err 返回，App 启动时返回这些错误
+func (r *Router) SetPanicOnConflict(enable bool) *Router {
+	r.panicOnConflict = enable
+	return r
+}
+
+// Err 返回注册路由时记录的冲突错误
+func (r *Router) Err() error {
+	return errors.Join(r.errs...)
 }
 
 // Handle  请求处理
 func (r *Router) Handle(c *Context) {
+	r.handle(c, nil, nil)
+}
+
+// handle 匹配路由并执行处理器，notFound/methodNotAllowed 为 App 级的处理器
+// 未注册 HEAD 的路径使用 GET 路由（响应体由 core 丢弃）；路径只在其他方法下注册时返回 405 和 Allow 头，
+// 未注册 OPTIONS 时自动回答 OPTIONS 请求
+func (r *Router) handle(c *Context, notFound, methodNotAllowed HandleChain) {
 
 	method := c.Method()
 	path := c.Path()
 
-	routeNode, ok := r.route[method]
-	if !ok {
-		HTTPNotFound(c)
+	if r.serveRoute(c, method, path) {
+		return
+	}
+	if method == http.MethodHead && r.serveRoute(c, http.MethodGet, path) {
 		return
 	}
 
+	allowed := r.allowedMethods(path)
+	if len(allowed) == 0 {
+		r.runFallback(c, r.groupNF, r.notFound, notFound, HTTPNotFound)
+		return
+	}
+	c.SetHeader("Allow", strings.Join(allowed, ", "))
+	if method == http.MethodOptions {
+		c.SetStatus(http.StatusNoContent)
+		_, _ = c.Write(nil)
+		c.Abort()
+		return
+	}
+	r.runFallback(c, r.groupMNA, r.methodNotAllowed, methodNotAllowed, HTTPMethodNotAllowed)
+}
+
+// serveRoute 在 method 的路由树中匹配 path，匹配成功时执行处理器并返回 true
+func (r *Router) serveRoute(c *Context, method, path string) bool {
+	routeNode, ok := r.route[method]
+	if !ok {
+		return false
+	}
 	matchedNode, params := routeNode.FindChild(path)
 	if matchedNode == nil || matchedNode.Handlers == nil {
-		HTTPNotFound(c)
-		return
+		return false
 	}
+	c.Params = params
+	runHandlers(c, matchedNode.Handlers)
+	return true
+}
+
+// allowedMethods 返回注册了 path 的方法，按字母排序并包含自动回答的 HEAD（有 GET 时）和 OPTIONS
+func (r *Router) allowedMethods(path string) []string {
+	allowed := make([]string, 0, len(r.route)+1)
+	for method, routeNode := range r.route {
+		matchedNode, _ := routeNode.FindChild(path)
+		if matchedNode != nil && matchedNode.Handlers != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	if slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
 
-	for key, value := range params {
-		c.SetParam(key, value)
+// runFallback 按 路由组（前缀最长者）→ 路由器 → App 的顺序选择处理器，都未设置时使用 def
+func (r *Router) runFallback(c *Context, groups prefixList[HandleChain], router, app HandleChain, def HandlerFunc) {
+	handlers := router
+	if g, ok := groups.lookup(c.Path()); ok {
+		handlers = g
+	}
+	if handlers == nil {
+		handlers = app
 	}
+	if handlers == nil {
+		def(c)
+		return
+	}
+	runHandlers(c, handlers)
+	c.Abort()
+}
 
-	for _, handler := range matchedNode.Handlers {
+// runHandlers 依次执行处理器，中止后不再执行后续处理器
+func runHandlers(c *Context, handlers HandleChain) {
+	for _, handler := range handlers {
 		if c.aborted {
 			return
 		}
@@ -193,6 +313,135 @@ func (r *Router) Handle(c *Context) {
 	}
 }
 
+// NotFound 设置路由器的 404 处理器，优先于 App 级的设置
+func (r *Router) NotFound(handlers ...HandlerFunc) {
+	r.notFound = handlers
+}
+
+// MethodNotAllowed 设置路由器的 405 处理器，优先于 App 级的设置，执行前已设置 Allow 头
+func (r *Router) MethodNotAllowed(handlers ...HandlerFunc) {
+	r.methodNotAllowed = handlers
+}
+
+// NotFound 设置路由组前缀下的 404 处理器，嵌套分组中前缀最长的设置生效
+// 处理器前会先执行注册时分组已有的中间件
+func (group *RouteGroup) NotFound(handlers ...HandlerFunc) {
+	chain := append(group.getAllHandlers(), handlers...)
+	group.router.groupNF.set(group.getFullPath(""), chain)
+}
+
+// MethodNotAllowed 设置路由组前缀下的 405 处理器，规则同 NotFound
+func (group *RouteGroup) MethodNotAllowed(handlers ...HandlerFunc) {
+	chain := append(group.getAllHandlers(), handlers...)
+	group.router.groupMNA.set(group.getFullPath(""), chain)
+}
+
+// prefixEntry 路由组前缀对应的设置
+type prefixEntry[T any] struct {
+	prefix string
+	value  T
+}
+
+// prefixList 按路由组前缀记录的设置（404/405 处理器、优先级），前缀可以包含 :param 和 *name 段，
+// 按段数从多到少、同一位置 静态 > 参数 > 通配符 排列，最具体的前缀生效
+type prefixList[T any] []prefixEntry[T]
+
+// set 记录前缀的设置，已有的前缀被覆盖
+func (l *prefixList[T]) set(prefix string, value T) {
+	if prefix == "" {
+		prefix = "/"
+	}
+	for i, e := range *l {
+		if e.prefix == prefix {
+			(*l)[i].value = value
+			return
+		}
+	}
+	*l = append(*l, prefixEntry[T]{prefix: prefix, value: value})
+	sort.SliceStable(*l, func(i, j int) bool {
+		return morePrecisePrefix((*l)[i].prefix, (*l)[j].prefix)
+	})
+}
+
+// lookup 返回覆盖 path 的最长前缀的设置
+func (l prefixList[T]) lookup(path string) (T, bool) {
+	for _, e := range l {
+		if hasPathPrefix(path, e.prefix) {
+			return e.value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// merge 合并另一个路由器的设置，已有的前缀保留当前设置
+func (l *prefixList[T]) merge(other prefixList[T]) {
+	for _, e := range other {
+		if !slices.ContainsFunc(*l, func(x prefixEntry[T]) bool { return x.prefix == e.prefix }) {
+			l.set(e.prefix, e.value)
+		}
+	}
+}
+
+// hasPathPrefix 判断 path 是否位于 prefix 之下，按路径段匹配（/api 不匹配 /apis）
+// prefix 中的 :param 段匹配任意非空的一段，*name 段匹配剩余的全部路径
+func hasPathPrefix(path, prefix string) bool {
+	if prefix == "/" {
+		return true
+	}
+	if !strings.ContainsAny(prefix, ":*") {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	rest := path
+	for _, seg := range prefixSegments(prefix) {
+		if seg[0] == '*' {
+			return true
+		}
+		if !strings.HasPrefix(rest, "/") {
+			return false
+		}
+		part := rest[1:]
+		rest = ""
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			part, rest = part[:i], part[i:]
+		}
+		if part == "" || (seg[0] != ':' && part != seg) {
+			return false
+		}
+	}
+	return true
+}
+
+// prefixSegments 返回前缀的非空路径段
+func prefixSegments(prefix string) []string {
+	return strings.FieldsFunc(prefix, func(r rune) bool { return r == '/' })
+}
+
+// segmentRank 路径段的匹配优先级：静态段 0、参数段 1、通配符段 2
+func segmentRank(seg string) int {
+	switch seg[0] {
+	case ':':
+		return 1
+	case '*':
+		return 2
+	}
+	return 0
+}
+
+// morePrecisePrefix 判断前缀 a 是否比 b 更具体：段数更多，或段数相同时第一个不同类型的段优先级更高
+func morePrecisePrefix(a, b string) bool {
+	sa, sb := prefixSegments(a), prefixSegments(b)
+	if len(sa) != len(sb) {
+		return len(sa) > len(sb)
+	}
+	for i := range sa {
+		if ra, rb := segmentRank(sa[i]), segmentRank(sb[i]); ra != rb {
+			return ra < rb
+		}
+	}
+	return false
+}
+
 // GET 添加GET请求路由
 func (r *Router) GET(path string, handler HandlerFunc) {
 	r.addRoute(path, "GET", HandleChain{handler})
@@ -228,59 +477,34 @@ func (r *Router) HEAD(path string, handler HandlerFunc) {
 	r.addRoute(path, "HEAD", HandleChain{handler})
 }
 
-// MergeRouter 合并另一个路由器的路由
+// MergeRouter 合并另一个路由器的路由，404/405 处理器和路由组优先级以当前路由器已有的设置为准，
+// 任一路由器开启 SetEmptyCatchAll 时合并后开启；路由逐条重新注册，与已有路由重复或冲突时按 SetPanicOnConflict 的设置报告，不会覆盖已有路由
 func (r *Router) MergeRouter(other *Router) {
-	for method, routeNode := range other.route {
-		if existingRoute, exists := r.route[method]; exists {
-			// 如果该HTTP方法已存在路由，则需要合并路由树
-			mergeRouteNodes(existingRoute, routeNode)
-		} else {
-			// 如果该HTTP方法不存在路由，则直接复制整个路由树
-			r.route[method] = routeNode
-		}
+	if r.notFound == nil {
+		r.notFound = other.notFound
 	}
-}
+	if r.methodNotAllowed == nil {
+		r.methodNotAllowed = other.methodNotAllowed
+	}
+	r.groupNF.merge(other.groupNF)
+	r.groupMNA.merge(other.groupMNA)
+	r.priorities.merge(other.priorities)
+	if other.emptyCatchAll && !r.emptyCatchAll {
+		r.SetEmptyCatchAll(true)
+	}
+	r.errs = append(r.errs, other.errs...)
 
-// mergeRouteNodes 合并两个路由节点树
-func mergeRouteNodes(existing, other *routeNode) {
-	// 这里需要实现具体的路由节点合并逻辑
-	// 简单的合并策略：other节点覆盖existing节点
-	// 但通常我们会想要递归合并而不是覆盖
-	if other.Handlers != nil {
-		existing.Handlers = other.Handlers
-	}
-	for _, otherChild := range other.children {
-		// 使用 indices 优化查找过程
-		var existingChild *routeNode
-		if len(otherChild.path) > 0 {
-			firstChar := otherChild.path[0:1]
-			// 检查第一个字符是否在 indices 中
-			if strings.Contains(existing.indices, firstChar) {
-				// 只在对应首字符的节点中查找
-				for _, ec := range existing.children {
-					if ec.path[0:1] == firstChar && ec.path == otherChild.path {
-						existingChild = ec
-						break
-					}
-				}
-			}
-		} else {
-			// 如果otherChild.path为空，则遍历所有子节点
-			for _, ec := range existing.children {
-				if ec.path == otherChild.path {
-					existingChild = ec
-					break
-				}
+	methods := make([]string, 0, len(other.route))
+	for method := range other.route {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		other.route[method].walk(func(n *routeNode) {
+			if n.Handlers != nil {
+				r.insert(n.info, n.Handlers)
 			}
-		}
-		if existingChild != nil {
-			// 如果找到匹配的子节点，递归合并
-			mergeRouteNodes(existingChild, otherChild)
-		} else {
-			// 如果没有匹配的子节点，直接添加
-			existing.children = append(existing.children, otherChild)
-			existing.indices += otherChild.path[0:1]
-		}
+		})
 	}
 }
 
@@ -295,14 +519,50 @@ type routeNode struct {
 	maxParams uint8        // 子树中最大参数数量
 	wildChild bool         // 是否有通配符子节点
 	paramName string
+
+	emptyCatchAll bool       // 根节点：通配符是否匹配空的剩余路径
+	info          *routeInfo // 注册该路由（或创建该节点）的位置，用于冲突报告
+}
+
+// routeInfo 路由的注册信息
+type routeInfo struct {
+	method  string
+	pattern string
+	source  string // 注册位置 file:line
+}
+
+func (i *routeInfo) String() string {
+	return fmt.Sprintf("%s %s (%s)", i.method, i.pattern, i.source)
+}
+
+// packagePrefix 本包函数名的前缀，用于在调用栈中跳过框架内部的帧
+var packagePrefix = strings.TrimSuffix(runtime.FuncForPC(reflect.ValueOf(NewRouter).Pointer()).Name(), "NewRouter")
+
+// callerSource 返回调用栈中第一个框架之外的位置
+func callerSource() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
 }
 
-func (r *routeNode) Insert(path string, handlers HandleChain) {
+// Insert 插入路由，路由重复、参数名冲突或通配符位置错误时返回错误且不修改已有路由
+func (r *routeNode) Insert(path string, handlers HandleChain, info *routeInfo) error {
 	if r == nil || len(path) == 0 {
-		return
+		return nil
 	}
 
 	parts := splitPath(path)
+	if err := validateParts(parts, info); err != nil {
+		return err
+	}
 	current := r
 
 	for _, part := range parts {
@@ -312,27 +572,34 @@ func (r *routeNode) Insert(path string, handlers HandleChain) {
 
 		var child *routeNode
 
-		// 使用 indices 优化查找过程
-		if len(part) > 0 {
-			firstChar := part[0:1]
-			// 检查第一个字符是否在 indices 中
-			if strings.Contains(current.indices, firstChar) {
-				// 只在对应首字符的节点中查找
+		switch part[0] {
+		case ':', '*':
+			// 同一位置只能有一个参数名（通配符同理），否则后注册的路由永远无法匹配
+			nType := param
+			if part[0] == '*' {
+				nType = catchAll
+			}
+			for _, c := range current.children {
+				if c.nType != nType {
+					continue
+				}
+				if c.path != part {
+					return fmt.Errorf("route %s conflicts with %s: %q and %q at the same position",
+						info, c.info, part, c.path)
+				}
+				child = c
+				break
+			}
+		default:
+			// 使用 indices 优化查找过程
+			if strings.Contains(current.indices, part[0:1]) {
 				for _, c := range current.children {
-					if c.path[0:1] == firstChar && c.path == part {
+					if c.nType == static && c.path == part {
 						child = c
 						break
 					}
 				}
 			}
-		} else {
-			// 如果part为空，则遍历所有子节点
-			for _, c := range current.children {
-				if c.path == part {
-					child = c
-					break
-				}
-			}
 		}
 
 		if child == nil {
@@ -346,6 +613,7 @@ func (r *routeNode) Insert(path string, handlers HandleChain) {
 				maxParams: 0,
 				wildChild: false,
 				paramName: "",
+				info:      info,
 			}
 
 			if part[0] == ':' {
@@ -353,7 +621,8 @@ func (r *routeNode) Insert(path string, handlers HandleChain) {
 				child.paramName = strings.TrimPrefix(part, ":")
 			} else if part[0] == '*' {
 				child.nType = catchAll
-				child.wildChild = true
+				child.paramName = strings.TrimPrefix(part, "*")
+				current.wildChild = true
 			} else {
 				child.nType = static
 			}
@@ -367,7 +636,36 @@ func (r *routeNode) Insert(path string, handlers HandleChain) {
 		current = child
 	}
 
+	if current.Handlers != nil {
+		return fmt.Errorf("route %s conflicts with %s: duplicate route", info, current.info)
+	}
 	current.Handlers = handlers
+	current.info = info
+	return nil
+}
+
+// validateParts 检查参数和通配符的写法：必须有名称，通配符必须是最后一段
+func validateParts(parts []string, info *routeInfo) error {
+	for i, part := range parts {
+		if part == "" || (part[0] != ':' && part[0] != '*') {
+			continue
+		}
+		if len(part) == 1 {
+			return fmt.Errorf("invalid route %s: %q must be named", info, part)
+		}
+		if part[0] == '*' && i != len(parts)-1 {
+			return fmt.Errorf("invalid route %s: catch-all %q must be the last segment", info, part)
+		}
+	}
+	return nil
+}
+
+// walk 深度优先遍历子树
+func (r *routeNode) walk(fn func(*routeNode)) {
+	fn(r)
+	for _, c := range r.children {
+		c.walk(fn)
+	}
 }
 
 func (r *routeNode) NewTire() *routeNode {
@@ -385,77 +683,80 @@ func (r *routeNode) NewTire() *routeNode {
 }
 
 // FindChild 获取路由
-func (r *routeNode) FindChild(path string) (*routeNode, map[string]string) {
+// 每一段按 静态 > 参数 > 通配符 的优先级匹配，某个分支在后续路径上匹配失败时回溯尝试下一个候选，
+// 例如注册了 /users/new/edit 和 /users/:id/profile 时，/users/new/profile 匹配后者
+// 通配符 *name 匹配剩余的全部路径（包括其中的斜杠），剩余为空时只在 SetEmptyCatchAll 开启后匹配
+// 返回的参数按在路径中出现的顺序排列
+func (r *routeNode) FindChild(path string) (*routeNode, Params) {
 	if r == nil || path == "" {
 		return nil, nil
 	}
 
-	parts := splitPath(path)
-	current := r
-	params := make(map[string]string)
+	params := make(Params, 0, r.maxParams)
+	matched := r.match(path, &params, r.emptyCatchAll)
+	if matched == nil {
+		return nil, nil
+	}
+	return matched, params
+}
 
-	for _, part := range parts {
-		if part == "" {
-			continue
+// match 从当前节点开始匹配剩余路径，返回注册了处理器的节点；连续的斜杠视为一个
+// 回溯时撤销失败分支写入的参数
+func (r *routeNode) match(path string, params *Params, emptyCatchAll bool) *routeNode {
+	path = strings.TrimLeft(path, "/")
+	if path == "" {
+		if r.Handlers != nil {
+			return r
 		}
-
-		var child *routeNode
-		found := false
-
-		if len(current.children) == 0 {
-			return nil, nil
+		if emptyCatchAll {
+			return r.matchCatchAll("", params)
 		}
+		return nil
+	}
 
-		// 首先尝试使用 indices 字段进行快速查找
-		if len(part) > 0 {
-			firstChar := part[0:1]
-			// 检查第一个字符是否在 indices 中
-			if strings.Contains(current.indices, firstChar) {
-				// 在 children 中只查找对应首字符的节点
-				for _, c := range current.children {
-					if c.path[0:1] == firstChar {
-						if c.path == part {
-							child = c
-							found = true
-							break
-						}
-					}
-				}
-			}
-		}
+	part, rest := path, ""
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		part, rest = path[:i], path[i:]
+	}
 
-		// 如果没有找到静态匹配，尝试参数节点
-		if !found {
-			for _, c := range current.children {
-				if c.nType == param {
-					child = c
-					found = true
-					params[c.paramName] = part
-					break
+	// 1. 静态节点，利用 indices 跳过首字符不同的子节点
+	if strings.Contains(r.indices, part[0:1]) {
+		for _, c := range r.children {
+			if c.nType == static && c.path == part {
+				if matched := c.match(rest, params, emptyCatchAll); matched != nil {
+					return matched
 				}
+				break
 			}
 		}
+	}
 
-		// 如果没有找到参数匹配，尝试通配符节点
-		if !found {
-			for _, c := range current.children {
-				if c.nType == catchAll && c.wildChild {
-					child = c
-					found = true
-					params[c.paramName] = part
-					break
-				}
-			}
+	// 2. 参数节点
+	for _, c := range r.children {
+		if c.nType != param {
+			continue
 		}
-
-		if !found {
-			return nil, nil
+		n := len(*params)
+		*params = append(*params, Param{Key: c.paramName, Value: part})
+		if matched := c.match(rest, params, emptyCatchAll); matched != nil {
+			return matched
 		}
-
-		current = child
+		*params = (*params)[:n]
 	}
 
-	return current, params
+	// 3. 通配符节点，捕获剩余的全部路径
+	return r.matchCatchAll(path, params)
+}
+
+// matchCatchAll 用通配符子节点匹配剩余路径
+func (r *routeNode) matchCatchAll(rest string, params *Params) *routeNode {
+	for _, c := range r.children {
+		if c.nType == catchAll && c.Handlers != nil {
+			*params = append(*params, Param{Key: c.paramName, Value: rest})
+			return c
+		}
+	}
+	return nil
 }
 
 func (r *routeNode) calculateMaxParams() uint8 {
@@ -0,0 +1,325 @@
+package FastGo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// serveRouter 用路由器处理一个请求
+func serveRouter(r *Router, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c := NewContext(nil, nil)
+	c.Reset(rec, httptest.NewRequest(method, path, nil))
+	r.Handle(c)
+	return rec
+}
+
+func TestMergeRouterSettings(t *testing.T) {
+	other := NewRouter()
+	other.GET("/files/*path", func(c *Context) { c.SendString(http.StatusOK, "files") })
+	other.Group("/export").SetPriority(PriorityLow)
+	other.Group("/api").SetPriority(PriorityLow)
+	other.SetEmptyCatchAll(true)
+
+	r := NewRouter()
+	r.Group("/api").SetPriority(PriorityHigh)
+	r.MergeRouter(other)
+
+	if got := r.priorityOf("/export/all"); got != PriorityLow {
+		t.Errorf("priorityOf(/export/all) = %d, want PriorityLow", got)
+	}
+	if got := r.priorityOf("/api/users"); got != PriorityHigh {
+		t.Errorf("priorityOf(/api/users) = %d, want PriorityHigh (existing setting wins)", got)
+	}
+	if rec := serveRouter(r, http.MethodGet, "/files"); rec.Code != http.StatusOK {
+		t.Errorf("GET /files = %d, want 200 with empty catch-all merged", rec.Code)
+	}
+}
+
+func TestRouterMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		routes        []string
+		emptyCatchAll bool
+		path          string
+		want          string            // 匹配到的路由，为空表示 404
+		params        map[string]string // 通过 GetPathParam 读取的参数，空字符串表示未设置
+	}{
+		{
+			name:   "static before param",
+			routes: []string{"/users/:id", "/users/new"},
+			path:   "/users/new",
+			want:   "/users/new",
+			params: map[string]string{"id": ""},
+		},
+		{
+			name:   "param before catch-all",
+			routes: []string{"/files/*path", "/files/:name"},
+			path:   "/files/a.txt",
+			want:   "/files/:name",
+			params: map[string]string{"name": "a.txt", "path": ""},
+		},
+		{
+			name:   "catch-all captures the rest",
+			routes: []string{"/files/*path", "/files/:name"},
+			path:   "/files/css/app.css",
+			want:   "/files/*path",
+			params: map[string]string{"path": "css/app.css", "name": ""},
+		},
+		{
+			name:   "backtrack from static",
+			routes: []string{"/users/new/edit", "/users/:id/profile"},
+			path:   "/users/new/profile",
+			want:   "/users/:id/profile",
+			params: map[string]string{"id": "new"},
+		},
+		{
+			name:   "backtrack from param",
+			routes: []string{"/a/:x/b", "/a/*rest"},
+			path:   "/a/1/c",
+			want:   "/a/*rest",
+			params: map[string]string{"rest": "1/c", "x": ""},
+		},
+		{
+			name:   "backtrack from static to catch-all",
+			routes: []string{"/assets/img/logo", "/assets/*path"},
+			path:   "/assets/img/icon",
+			want:   "/assets/*path",
+			params: map[string]string{"path": "img/icon"},
+		},
+		{
+			name:   "multiple params",
+			routes: []string{"/orgs/:org/repos/:repo"},
+			path:   "/orgs/go/repos/tools",
+			want:   "/orgs/:org/repos/:repo",
+			params: map[string]string{"org": "go", "repo": "tools"},
+		},
+		{
+			name:   "empty catch-all off",
+			routes: []string{"/static/*filepath"},
+			path:   "/static/",
+		},
+		{
+			name:   "empty catch-all off without slash",
+			routes: []string{"/static/*filepath"},
+			path:   "/static",
+		},
+		{
+			name:          "empty catch-all on",
+			routes:        []string{"/static/*filepath"},
+			emptyCatchAll: true,
+			path:          "/static/",
+			want:          "/static/*filepath",
+			params:        map[string]string{"filepath": ""},
+		},
+		{
+			name:          "empty catch-all on without slash",
+			routes:        []string{"/static/*filepath"},
+			emptyCatchAll: true,
+			path:          "/static",
+			want:          "/static/*filepath",
+			params:        map[string]string{"filepath": ""},
+		},
+		{
+			name:   "no match",
+			routes: []string{"/users/:id"},
+			path:   "/users/1/profile",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter().SetEmptyCatchAll(tt.emptyCatchAll)
+			var got map[string]string
+			for _, route := range tt.routes {
+				r.GET(route, func(c *Context) {
+					got = make(map[string]string, len(tt.params))
+					for key := range tt.params {
+						got[key] = c.GetPathParam(key)
+					}
+					c.SendString(http.StatusOK, route)
+				})
+			}
+
+			rec := serveRouter(r, http.MethodGet, tt.path)
+			if tt.want == "" {
+				if rec.Code != http.StatusNotFound {
+					t.Fatalf("GET %s = %d %q, want 404", tt.path, rec.Code, rec.Body.String())
+				}
+				return
+			}
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+				t.Fatalf("GET %s = %d %q, want 200 %q", tt.path, rec.Code, rec.Body.String(), tt.want)
+			}
+			for key, want := range tt.params {
+				if got[key] != want {
+					t.Errorf("GetPathParam(%q) = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
+
+// lineAbove 返回调用处上一行的 file:line，即上一行注册路由的位置
+func lineAbove() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file, line-1)
+}
+
+func TestRouterConflict(t *testing.T) {
+	h := func(c *Context) {}
+	tests := []struct {
+		name  string
+		setup func(r *Router) []string // 注册路由，返回错误信息中应出现的注册位置
+		want  string                   // 错误信息片段，为空表示没有冲突
+	}{
+		{
+			name: "duplicate route",
+			setup: func(r *Router) []string {
+				r.GET("/users", h)
+				first := lineAbove()
+				r.GET("/users", h)
+				return []string{first, lineAbove()}
+			},
+			want: "duplicate route",
+		},
+		{
+			name: "param name",
+			setup: func(r *Router) []string {
+				r.GET("/users/:id", h)
+				first := lineAbove()
+				r.GET("/users/:uid", h)
+				return []string{first, lineAbove()}
+			},
+			want: `":uid" and ":id" at the same position`,
+		},
+		{
+			name: "catch-all not last",
+			setup: func(r *Router) []string {
+				r.GET("/static/*path/meta", h)
+				return []string{lineAbove()}
+			},
+			want: `catch-all "*path" must be the last segment`,
+		},
+		{
+			name: "unnamed catch-all",
+			setup: func(r *Router) []string {
+				r.GET("/static/*", h)
+				return []string{lineAbove()}
+			},
+			want: `"*" must be named`,
+		},
+		{
+			name: "merge router",
+			setup: func(r *Router) []string {
+				r.GET("/users/:id", h)
+				first := lineAbove()
+				other := NewRouter()
+				other.GET("/users/:name", h)
+				second := lineAbove()
+				r.MergeRouter(other)
+				return []string{first, second}
+			},
+			want: `":name" and ":id" at the same position`,
+		},
+		{
+			name: "same route on another method",
+			setup: func(r *Router) []string {
+				r.GET("/users/:id", h)
+				r.POST("/users/:id", h)
+				r.GET("/users/new", h)
+				return nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter().SetPanicOnConflict(false)
+			sources := tt.setup(r)
+			err := r.Err()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Err() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Err() = %v, want %q", err, tt.want)
+			}
+			for _, source := range sources {
+				if !strings.Contains(err.Error(), "("+source+")") {
+					t.Errorf("Err() = %v, want source %s", err, source)
+				}
+			}
+
+			// 默认注册冲突时 panic
+			defer func() {
+				v := recover()
+				if err, ok := v.(error); !ok || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("recover() = %v, want panic with %q", v, tt.want)
+				}
+			}()
+			tt.setup(NewRouter())
+		})
+	}
+}
+
+func TestGroupFallbackPrefix(t *testing.T) {
+	r := NewRouter()
+	r.GET("/users/:id/profile", func(c *Context) {})
+	fallback := func(body string) HandlerFunc {
+		return func(c *Context) { c.SendString(http.StatusNotFound, body) }
+	}
+	r.Group("/users").NotFound(fallback("users"))
+	r.Group("/users/:id").NotFound(fallback("user"))
+	r.Group("/users/admin").NotFound(fallback("admin"))
+	r.Group("/files/*path").NotFound(fallback("files"))
+	r.Group("/users/:id").MethodNotAllowed(func(c *Context) {
+		c.SendString(http.StatusMethodNotAllowed, "user 405")
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{http.MethodGet, "/users/5/nope", http.StatusNotFound, "user"},
+		{http.MethodGet, "/users/5", http.StatusNotFound, "user"},
+		{http.MethodGet, "/users/admin/nope", http.StatusNotFound, "admin"},
+		{http.MethodGet, "/users", http.StatusNotFound, "users"},
+		{http.MethodGet, "/files/a/b", http.StatusNotFound, "files"},
+		{http.MethodGet, "/other", http.StatusNotFound, "404 Not Found"},
+		{http.MethodPost, "/users/5/profile", http.StatusMethodNotAllowed, "user 405"},
+	}
+	for _, tt := range tests {
+		rec := serveRouter(r, tt.method, tt.path)
+		if rec.Code != tt.status || strings.TrimSpace(rec.Body.String()) != tt.body {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, rec.Code, rec.Body.String(), tt.status, tt.body)
+		}
+	}
+}
+
+func TestRouterParamsOrder(t *testing.T) {
+	r := NewRouter()
+	var got Params
+	r.GET("/orgs/:org/repos/:repo/*path", func(c *Context) {
+		got = append(Params(nil), c.Params...)
+	})
+	want := Params{{Key: "org", Value: "go"}, {Key: "repo", Value: "tools"}, {Key: "path", Value: "cmd/vet"}}
+
+	for i := 0; i < 20; i++ {
+		rec := httptest.NewRecorder()
+		c := NewContext(nil, nil)
+		c.Reset(rec, httptest.NewRequest(http.MethodGet, "/orgs/go/repos/tools/cmd/vet", nil))
+		c.SetParam("org", "stale")
+		r.Handle(c)
+		if !slices.Equal(got, want) {
+			t.Fatalf("Params = %v, want %v", got, want)
+		}
+	}
+}